@@ -0,0 +1,47 @@
+package importer
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bashHistoryImporter parses a ~/.bash_history file. Bash's history format is
+// one command per line, optionally preceded by a "#<unix-ts>" comment line
+// when HISTTIMEFORMAT is enabled; when present, that timestamp is attached to
+// the command that follows it.
+type bashHistoryImporter struct{}
+
+func newBashHistoryImporter() *bashHistoryImporter { return &bashHistoryImporter{} }
+
+func (b *bashHistoryImporter) Parse(r io.Reader) ([]Command, error) {
+	var commands []Command
+	var pendingTs *time.Time
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			if unixSeconds, err := strconv.ParseInt(strings.TrimPrefix(line, "#"), 10, 64); err == nil {
+				ts := time.Unix(unixSeconds, 0).UTC()
+				pendingTs = &ts
+				continue
+			}
+			// Not a timestamp comment; fall through and treat as a command.
+		}
+
+		commands = append(commands, Command{Command: line, StartTs: pendingTs})
+		pendingTs = nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return commands, nil
+}
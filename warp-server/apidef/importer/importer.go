@@ -0,0 +1,137 @@
+// Package importer normalizes command and AI-query histories from external
+// formats (Swagger/OpenAPI specs, shell history files, ...) into the same
+// shape the Warp local DB already stores them in, so they can be ingested
+// through the existing `commands` / `ai_queries` tables.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Command mirrors the row shape returned by RecentCommandsHandler, so
+// imported rows can be inserted with the same INSERT statement used for
+// commands that originate from the terminal itself.
+type Command struct {
+	Command     string
+	ExitCode    *int
+	StartTs     *time.Time
+	CompletedTs *time.Time
+	Pwd         *string
+	Shell       *string
+	Username    *string
+	Hostname    *string
+}
+
+// AIQuery mirrors the row shape returned by RecentAIQueriesHandler.
+type AIQuery struct {
+	ExchangeId       string
+	ConversationId   string
+	StartTs          *time.Time
+	Input            string
+	WorkingDirectory *string
+	OutputStatus     string
+	ModelId          string
+}
+
+// Importer parses a source document into commands to be merged into the
+// `commands` table. New formats are added by implementing this interface and
+// registering an instance with Register, without touching any handler.
+type Importer interface {
+	Parse(r io.Reader) ([]Command, error)
+}
+
+// AIQueryImporter parses a source document into AI queries to be merged
+// into the `ai_queries` table. New formats are added by implementing this
+// interface and registering an instance with RegisterAIQueryImporter,
+// without touching any handler.
+type AIQueryImporter interface {
+	Parse(r io.Reader) ([]AIQuery, error)
+}
+
+var (
+	mu        sync.RWMutex
+	importers = map[string]Importer{}
+
+	aiQueryMu        sync.RWMutex
+	aiQueryImporters = map[string]AIQueryImporter{}
+)
+
+// Register associates a format name (as passed via ?format=) with an
+// Importer implementation. Calling Register with a name that is already
+// registered replaces the previous importer.
+func Register(format string, imp Importer) {
+	mu.Lock()
+	defer mu.Unlock()
+	importers[format] = imp
+}
+
+// Get returns the importer registered for format, if any.
+func Get(format string) (Importer, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	imp, ok := importers[format]
+	return imp, ok
+}
+
+// Formats returns the list of currently registered format names.
+func Formats() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	formats := make([]string, 0, len(importers))
+	for format := range importers {
+		formats = append(formats, format)
+	}
+	return formats
+}
+
+// RegisterAIQueryImporter associates a format name (as passed via ?format=)
+// with an AIQueryImporter implementation. Calling RegisterAIQueryImporter
+// with a name that is already registered replaces the previous importer.
+func RegisterAIQueryImporter(format string, imp AIQueryImporter) {
+	aiQueryMu.Lock()
+	defer aiQueryMu.Unlock()
+	aiQueryImporters[format] = imp
+}
+
+// GetAIQueryImporter returns the AIQueryImporter registered for format, if
+// any.
+func GetAIQueryImporter(format string) (AIQueryImporter, bool) {
+	aiQueryMu.RLock()
+	defer aiQueryMu.RUnlock()
+	imp, ok := aiQueryImporters[format]
+	return imp, ok
+}
+
+// AIQueryFormats returns the list of currently registered AI-query format
+// names.
+func AIQueryFormats() []string {
+	aiQueryMu.RLock()
+	defer aiQueryMu.RUnlock()
+	formats := make([]string, 0, len(aiQueryImporters))
+	for format := range aiQueryImporters {
+		formats = append(formats, format)
+	}
+	return formats
+}
+
+func init() {
+	Register("swagger", newSwaggerImporter())
+	Register("openapi", newOpenAPIImporter())
+	Register("bash_history", newBashHistoryImporter())
+	Register("zsh_history", newZshHistoryImporter())
+
+	RegisterAIQueryImporter("warp_ai_export", newWarpAIExportImporter())
+}
+
+// ErrUnknownFormat is returned by handlers when format does not match any
+// registered Importer.
+type ErrUnknownFormat struct {
+	Format string
+}
+
+func (e *ErrUnknownFormat) Error() string {
+	return fmt.Sprintf("unknown import format: %q", e.Format)
+}
@@ -0,0 +1,117 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBashHistoryImporter_Parse(t *testing.T) {
+	input := "ls -la\n#1627890000\ngit status\n"
+
+	commands, err := newBashHistoryImporter().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(commands) != 2 {
+		t.Fatalf("expected 2 commands, got %d", len(commands))
+	}
+
+	if commands[0].Command != "ls -la" || commands[0].StartTs != nil {
+		t.Errorf("unexpected first command: %+v", commands[0])
+	}
+
+	if commands[1].Command != "git status" || commands[1].StartTs == nil {
+		t.Errorf("expected second command to carry a timestamp: %+v", commands[1])
+	}
+}
+
+func TestZshHistoryImporter_Parse(t *testing.T) {
+	input := ": 1627890000:0;git status\nplain command\n"
+
+	commands, err := newZshHistoryImporter().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(commands) != 2 {
+		t.Fatalf("expected 2 commands, got %d", len(commands))
+	}
+
+	if commands[0].Command != "git status" || commands[0].StartTs == nil {
+		t.Errorf("unexpected first command: %+v", commands[0])
+	}
+
+	if commands[1].Command != "plain command" || commands[1].StartTs != nil {
+		t.Errorf("unexpected second command: %+v", commands[1])
+	}
+}
+
+func TestSwaggerImporter_Parse(t *testing.T) {
+	input := `{
+		"paths": {
+			"/commands": {
+				"get": {"operationId": "listCommands"}
+			}
+		}
+	}`
+
+	commands, err := newSwaggerImporter().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(commands) != 1 {
+		t.Fatalf("expected 1 command, got %d", len(commands))
+	}
+
+	if commands[0].Command != "get /commands # listCommands" {
+		t.Errorf("unexpected command: %q", commands[0].Command)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	for _, format := range []string{"swagger", "openapi", "bash_history", "zsh_history"} {
+		if _, ok := Get(format); !ok {
+			t.Errorf("expected format %q to be registered", format)
+		}
+	}
+
+	if _, ok := Get("does_not_exist"); ok {
+		t.Errorf("expected unregistered format to be absent")
+	}
+}
+
+func TestWarpAIExportImporter_Parse(t *testing.T) {
+	input := `[
+		{"exchange_id": "e1", "conversation_id": "c1", "input": "list files", "output_status": "success", "model_id": "gpt-4"},
+		{"exchange_id": "e2", "conversation_id": "c1", "input": "now delete them", "output_status": "error", "model_id": "gpt-4"}
+	]`
+
+	queries, err := newWarpAIExportImporter().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("expected 2 AI queries, got %d", len(queries))
+	}
+
+	if queries[0].ExchangeId != "e1" || queries[0].Input != "list files" {
+		t.Errorf("unexpected first query: %+v", queries[0])
+	}
+
+	if queries[1].ExchangeId != "e2" || queries[1].OutputStatus != "error" {
+		t.Errorf("unexpected second query: %+v", queries[1])
+	}
+}
+
+func TestAIQueryImporterRegistry(t *testing.T) {
+	if _, ok := GetAIQueryImporter("warp_ai_export"); !ok {
+		t.Errorf("expected format %q to be registered", "warp_ai_export")
+	}
+
+	if _, ok := GetAIQueryImporter("does_not_exist"); ok {
+		t.Errorf("expected unregistered format to be absent")
+	}
+}
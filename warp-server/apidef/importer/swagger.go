@@ -0,0 +1,71 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// apiSpec models the subset of Swagger 2.0 / OpenAPI 3.0 that import cares
+// about: the map of paths to HTTP operations. Both formats describe
+// operations identically under "paths", so a single struct covers both.
+type apiSpec struct {
+	Paths map[string]map[string]struct {
+		OperationId string `json:"operationId"`
+		Summary     string `json:"summary"`
+	} `json:"paths"`
+}
+
+// specImporter turns a Swagger/OpenAPI document describing a terminal
+// automation API into one synthetic Command per documented operation, so the
+// documented surface can be diffed against what was actually run.
+type specImporter struct {
+	kind string // used only for error messages, e.g. "swagger" or "openapi"
+}
+
+func newSwaggerImporter() *specImporter { return &specImporter{kind: "swagger"} }
+func newOpenAPIImporter() *specImporter { return &specImporter{kind: "openapi"} }
+
+func (s *specImporter) Parse(r io.Reader) ([]Command, error) {
+	var spec apiSpec
+	if err := json.NewDecoder(r).Decode(&spec); err != nil {
+		return nil, fmt.Errorf("parsing %s document: %w", s.kind, err)
+	}
+
+	// Sort paths for deterministic output since map iteration order isn't.
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var commands []Command
+	for _, path := range paths {
+		methods := make([]string, 0, len(spec.Paths[path]))
+		for method := range spec.Paths[path] {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			op := spec.Paths[path][method]
+			label := op.OperationId
+			if label == "" {
+				label = op.Summary
+			}
+
+			cmd := formatOperationCommand(method, path, label)
+			commands = append(commands, Command{Command: cmd})
+		}
+	}
+
+	return commands, nil
+}
+
+func formatOperationCommand(method, path, label string) string {
+	if label == "" {
+		return fmt.Sprintf("%s %s", method, path)
+	}
+	return fmt.Sprintf("%s %s # %s", method, path, label)
+}
@@ -0,0 +1,48 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// aiExportEntry mirrors a single exchange in Warp's own AI query export
+// format: a JSON array of conversation turns, one object per exchange.
+type aiExportEntry struct {
+	ExchangeId       string     `json:"exchange_id"`
+	ConversationId   string     `json:"conversation_id"`
+	StartTs          *time.Time `json:"start_ts"`
+	Input            string     `json:"input"`
+	WorkingDirectory *string    `json:"working_directory"`
+	OutputStatus     string     `json:"output_status"`
+	ModelId          string     `json:"model_id"`
+}
+
+// warpAIExportImporter parses Warp's AI query export format, the natural
+// interchange format for moving AI history between installs.
+type warpAIExportImporter struct{}
+
+func newWarpAIExportImporter() *warpAIExportImporter { return &warpAIExportImporter{} }
+
+func (w *warpAIExportImporter) Parse(r io.Reader) ([]AIQuery, error) {
+	var entries []aiExportEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("parsing warp_ai_export document: %w", err)
+	}
+
+	queries := make([]AIQuery, len(entries))
+	for i, entry := range entries {
+		queries[i] = AIQuery{
+			ExchangeId:       entry.ExchangeId,
+			ConversationId:   entry.ConversationId,
+			StartTs:          entry.StartTs,
+			Input:            entry.Input,
+			WorkingDirectory: entry.WorkingDirectory,
+			OutputStatus:     entry.OutputStatus,
+			ModelId:          entry.ModelId,
+		}
+	}
+
+	return queries, nil
+}
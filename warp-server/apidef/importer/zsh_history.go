@@ -0,0 +1,70 @@
+package importer
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// zshHistoryImporter parses zsh's "extended history" format
+// (`setopt EXTENDED_HISTORY`), where each line looks like:
+//
+//	: 1627890000:0;some command here
+//
+// the two numbers being the start timestamp and duration in seconds. Lines
+// without that prefix are treated as plain commands.
+type zshHistoryImporter struct{}
+
+func newZshHistoryImporter() *zshHistoryImporter { return &zshHistoryImporter{} }
+
+func (z *zshHistoryImporter) Parse(r io.Reader) ([]Command, error) {
+	var commands []Command
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		if cmd, ts, ok := parseExtendedHistoryLine(line); ok {
+			commands = append(commands, Command{Command: cmd, StartTs: ts})
+			continue
+		}
+
+		commands = append(commands, Command{Command: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return commands, nil
+}
+
+func parseExtendedHistoryLine(line string) (command string, startTs *time.Time, ok bool) {
+	if !strings.HasPrefix(line, ": ") {
+		return "", nil, false
+	}
+
+	rest := strings.TrimPrefix(line, ": ")
+	semicolon := strings.Index(rest, ";")
+	if semicolon < 0 {
+		return "", nil, false
+	}
+
+	meta, cmd := rest[:semicolon], rest[semicolon+1:]
+	colon := strings.Index(meta, ":")
+	if colon < 0 {
+		return "", nil, false
+	}
+
+	unixSeconds, err := strconv.ParseInt(meta[:colon], 10, 64)
+	if err != nil {
+		return "", nil, false
+	}
+
+	ts := time.Unix(unixSeconds, 0).UTC()
+	return cmd, &ts, true
+}
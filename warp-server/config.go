@@ -1,8 +1,14 @@
 package main
 
 import (
+	"database/sql"
+	"log"
 	"os"
 	"strconv"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
 )
 
 // Config holds the application configuration
@@ -11,16 +17,51 @@ type Config struct {
 	Environment  string
 	LogLevel     string
 	DatabasePath string
+
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	IdleTimeout    time.Duration
+	RequestTimeout time.Duration
+
+	// DB is a connection pool opened once for the lifetime of the process.
+	// Handlers should query through it instead of opening their own
+	// sql.Open("sqlite3", ...) connection per request.
+	DB *sql.DB
 }
 
-// LoadConfig loads configuration from environment variables
+var (
+	configOnce sync.Once
+	config     *Config
+)
+
+// LoadConfig loads configuration from environment variables. The Config
+// (and its DB pool) is built once per process; every subsequent call returns
+// the same instance.
 func LoadConfig() *Config {
-	return &Config{
-		Port:         getEnv("PORT", "8080"),
-		Environment:  getEnv("ENV", "development"),
-		LogLevel:     getEnv("LOG_LEVEL", "info"),
-		DatabasePath: getEnv("DATABASE_PATH", "/Users/KhulnaSoft/Library/Application Support/dev.warp.Warp-Preview/warp.sqlite"),
-	}
+	configOnce.Do(func() {
+		cfg := &Config{
+			Port:         getEnv("PORT", "8080"),
+			Environment:  getEnv("ENV", "development"),
+			LogLevel:     getEnv("LOG_LEVEL", "info"),
+			DatabasePath: getEnv("DATABASE_PATH", "/Users/KhulnaSoft/Library/Application Support/dev.warp.Warp-Preview/warp.sqlite"),
+
+			ReadTimeout:    time.Duration(getEnvAsInt("READ_TIMEOUT_SECONDS", 15)) * time.Second,
+			WriteTimeout:   time.Duration(getEnvAsInt("WRITE_TIMEOUT_SECONDS", 15)) * time.Second,
+			IdleTimeout:    time.Duration(getEnvAsInt("IDLE_TIMEOUT_SECONDS", 60)) * time.Second,
+			RequestTimeout: time.Duration(getEnvAsInt("REQUEST_TIMEOUT_SECONDS", 10)) * time.Second,
+		}
+
+		db, err := sql.Open("sqlite3", cfg.DatabasePath)
+		if err != nil {
+			log.Printf("Failed to open database pool at %s: %v", cfg.DatabasePath, err)
+		} else {
+			cfg.DB = db
+		}
+
+		config = cfg
+	})
+
+	return config
 }
 
 // getEnv gets an environment variable with a fallback default value
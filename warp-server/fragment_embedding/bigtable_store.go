@@ -0,0 +1,55 @@
+package fragment_embedding
+
+import (
+	"context"
+
+	"github.com/warpdotdev/warp-server/logic/ai/context_retrieval/codebase/merkle_tree/bigtable"
+	"github.com/warpdotdev/warp-server/model/types/ai"
+	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
+)
+
+// BigtableStore adapts a *bigtable.Client to EmbeddingStore, scoping every
+// read and write to a single repoID and tagging written rows with model.
+type BigtableStore struct {
+	client *bigtable.Client
+	repoID string
+	model  string
+}
+
+// NewBigtableStore returns an EmbeddingStore backed by client, persisting
+// embeddings for repoID under the given model name.
+func NewBigtableStore(client *bigtable.Client, repoID, model string) *BigtableStore {
+	return &BigtableStore{client: client, repoID: repoID, model: model}
+}
+
+// ExistingHashes implements EmbeddingStore by reading back each content hash
+// individually, since bigtable.Client has no bulk existence check.
+func (s *BigtableStore) ExistingHashes(ctx context.Context, contentHashes []string) ([]ai.ContentHashJbool, error) {
+	result := make([]ai.ContentHashJbool, len(contentHashes))
+	for i, hash := range contentHashes {
+		emb, err := s.client.ReadEmbedding(ctx, s.repoID, hash)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = ai.ContentHashJbool{Hash: hash, Exists: emb != nil}
+	}
+	return result, nil
+}
+
+// Write implements EmbeddingStore. Bigtable mutations are last-write-wins
+// with no compare-and-swap primitive, so Write never returns
+// ErrWriteConflict; UpsertIfChanged's conflict-retry path is simply unused
+// against this store.
+func (s *BigtableStore) Write(ctx context.Context, contentHash string, result *graphql_types.GenerateCodeEmbeddingResult) error {
+	vector := make([]float64, len(result.Vector))
+	for i, f := range result.Vector {
+		vector[i] = float64(f)
+	}
+
+	return s.client.WriteEmbeddings(ctx, s.repoID, []ai.Embedding{{
+		Hash:      contentHash,
+		Vector:    vector,
+		Model:     s.model,
+		Dimension: len(vector),
+	}})
+}
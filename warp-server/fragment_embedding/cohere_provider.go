@@ -0,0 +1,112 @@
+package fragment_embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
+	"github.com/warpdotdev/warp-server/pkg/retry"
+)
+
+// cohereProvider calls Cohere's /v1/embed endpoint.
+type cohereProvider struct {
+	apiKey     string
+	baseURL    string
+	model      string
+	inputType  string
+	dimension  int
+	httpClient *http.Client
+	maxRetries int
+}
+
+// cohereMaxBatchSize mirrors Cohere's documented limit on texts per embed
+// request.
+const cohereMaxBatchSize = 96
+
+func newCohereProvider(cfg graphql_types.EmbeddingConfig) (Provider, error) {
+	return &cohereProvider{
+		apiKey:     os.Getenv("COHERE_API_KEY"),
+		baseURL:    "https://api.cohere.ai/v1",
+		model:      cfg.Model,
+		inputType:  "search_document",
+		dimension:  cfg.Dimension,
+		httpClient: http.DefaultClient,
+		maxRetries: 3,
+	}, nil
+}
+
+func (p *cohereProvider) Name() string      { return "cohere" }
+func (p *cohereProvider) Dimension() int    { return p.dimension }
+func (p *cohereProvider) MaxBatchSize() int { return cohereMaxBatchSize }
+
+type cohereEmbeddingRequest struct {
+	Model     string   `json:"model"`
+	Texts     []string `json:"texts"`
+	InputType string   `json:"input_type"`
+}
+
+type cohereEmbeddingResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// Embed implements Provider.
+func (p *cohereProvider) Embed(ctx context.Context, batch []Request) ([]*graphql_types.GenerateCodeEmbeddingResult, error) {
+	texts := make([]string, len(batch))
+	for i, request := range batch {
+		texts[i] = request.Fragment.Content
+	}
+
+	var results []*graphql_types.GenerateCodeEmbeddingResult
+	err := retry.Do(ctx, p.maxRetries, func() error {
+		payload, err := json.Marshal(cohereEmbeddingRequest{Model: p.model, Texts: texts, InputType: p.inputType})
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/embed", bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+		resp, err := p.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("cohere embed request failed with status %d", resp.StatusCode)
+		}
+
+		var parsed cohereEmbeddingResponse
+		if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+			return err
+		}
+
+		batchResults := make([]*graphql_types.GenerateCodeEmbeddingResult, len(batch))
+		for i, request := range batch {
+			if i >= len(parsed.Embeddings) {
+				batchResults[i] = failedResult(request)
+				continue
+			}
+			batchResults[i] = &graphql_types.GenerateCodeEmbeddingResult{
+				Hash:    request.Fragment.ContentHash,
+				Success: true,
+				Vector:  parsed.Embeddings[i],
+			}
+		}
+		results = batchResults
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
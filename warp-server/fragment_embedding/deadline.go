@@ -0,0 +1,85 @@
+package fragment_embedding
+
+import (
+	"sync"
+	"time"
+)
+
+// deadlineTimer guards a single in-flight batch RPC with an optional
+// wall-clock deadline, modeled on net.Conn's internal setDeadline: cancelCh
+// is closed at most once, and only by the armed timer. A later call to
+// SetBatchDeadline stops that timer and replaces cancelCh with a fresh
+// channel, leaving the old one open.
+type deadlineTimer struct {
+	mu       sync.Mutex
+	cancelCh chan struct{}
+	timer    *time.Timer
+}
+
+// newDeadlineTimer returns a deadlineTimer with no deadline armed; its
+// channel only ever fires once SetBatchDeadline is called with a non-zero
+// time.
+func newDeadlineTimer() *deadlineTimer {
+	return &deadlineTimer{cancelCh: make(chan struct{})}
+}
+
+// SetBatchDeadline stops any previously armed timer and, unless t is zero,
+// schedules cancelCh to close at t.
+func (d *deadlineTimer) SetBatchDeadline(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.cancelCh = make(chan struct{})
+
+	if t.IsZero() {
+		d.timer = nil
+		return
+	}
+
+	cancelCh := d.cancelCh
+	d.timer = time.AfterFunc(time.Until(t), func() { close(cancelCh) })
+}
+
+// channel returns the cancelCh currently armed by SetBatchDeadline.
+func (d *deadlineTimer) channel() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.cancelCh
+}
+
+// stop disarms the timer, releasing it without closing cancelCh.
+func (d *deadlineTimer) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+}
+
+// embedOptions holds the resolved effect of every Option passed to
+// GenerateEmbeddings.
+type embedOptions struct {
+	batchTimeout time.Duration
+}
+
+// Option configures optional behavior for GenerateEmbeddings.
+type Option func(*embedOptions)
+
+// WithBatchTimeout bounds the wall-clock time allowed for each provider
+// batch RPC, independent of ctx's own deadline. Use this to cap per-request
+// latency in an HTTP handler without cancelling ctx (and whatever else is
+// scoped to it) out from under a slow provider call.
+func WithBatchTimeout(d time.Duration) Option {
+	return func(o *embedOptions) { o.batchTimeout = d }
+}
+
+func resolveOptions(opts []Option) embedOptions {
+	var resolved embedOptions
+	for _, opt := range opts {
+		opt(&resolved)
+	}
+	return resolved
+}
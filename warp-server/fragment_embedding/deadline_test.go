@@ -0,0 +1,66 @@
+package fragment_embedding
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warpdotdev/warp-server/model/types"
+	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
+)
+
+// slowProvider blocks every Embed call until unblock is closed, so tests
+// can exercise WithBatchTimeout against an RPC that's genuinely in flight
+// rather than one that merely hasn't started yet.
+type slowProvider struct {
+	unblock chan struct{}
+}
+
+func (p *slowProvider) Name() string      { return "slow" }
+func (p *slowProvider) Dimension() int    { return 1 }
+func (p *slowProvider) MaxBatchSize() int { return 0 }
+
+func (p *slowProvider) Embed(ctx context.Context, batch []Request) ([]*graphql_types.GenerateCodeEmbeddingResult, error) {
+	select {
+	case <-p.unblock:
+	case <-ctx.Done():
+	}
+	results := make([]*graphql_types.GenerateCodeEmbeddingResult, len(batch))
+	for i, request := range batch {
+		results[i] = &graphql_types.GenerateCodeEmbeddingResult{Hash: request.Fragment.ContentHash, Success: true}
+	}
+	return results, nil
+}
+
+func TestGenerateEmbeddings_BatchTimeoutAbandonsInFlightCall(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+	Register("slow", func(cfg graphql_types.EmbeddingConfig) (Provider, error) {
+		return &slowProvider{unblock: unblock}, nil
+	})
+
+	fragments := []types.Fragment{{Hash: "a", ContentHash: "hash1", Content: "x"}}
+	cfg := graphql_types.EmbeddingConfig{Provider: "slow"}
+
+	start := time.Now()
+	results, err := GenerateEmbeddings(context.Background(), fragments, cfg, WithBatchTimeout(10*time.Millisecond))
+	elapsed := time.Since(start)
+
+	assert.Error(t, err)
+	assert.Less(t, elapsed, time.Second, "GenerateEmbeddings should not wait for the slow provider call to finish")
+	assert.Len(t, results, 1)
+	assert.False(t, results[0].Success)
+	assert.Equal(t, "hash1", results[0].Hash)
+}
+
+func TestGenerateEmbeddings_BatchTimeoutDoesNotFireWhenProviderIsFast(t *testing.T) {
+	fragments := []types.Fragment{{Hash: "a", ContentHash: "hash1", Content: "x"}}
+	cfg := graphql_types.EmbeddingConfig{Provider: "mock"}
+
+	results, err := GenerateEmbeddings(context.Background(), fragments, cfg, WithBatchTimeout(time.Second))
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.True(t, results[0].Success)
+}
@@ -2,15 +2,45 @@ package fragment_embedding
 
 import (
 	"context"
-	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
+	"fmt"
+	"math"
+	"runtime"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
 	"github.com/warpdotdev/warp-server/model/types"
+	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
 )
 
-// GenerateEmbeddings generates embeddings for code fragments
-func GenerateEmbeddings(ctx context.Context, fragments []types.Fragment) ([]*graphql_types.GenerateCodeEmbeddingResult, error) {
-	results := make([]*graphql_types.GenerateCodeEmbeddingResult, len(fragments))
-	
-	// Create batch from fragments
+// defaultParallelThreshold is the fragment count above which GenerateEmbeddings
+// switches from its serial path to a sharded worker pool, used when
+// EmbeddingConfig.ParallelThreshold isn't set.
+const defaultParallelThreshold = 100
+
+// GenerateEmbeddings generates embeddings for code fragments using the
+// Provider registered under cfg.Provider. Batches at or below
+// cfg.ParallelThreshold (defaultParallelThreshold if unset) are walked
+// serially; larger batches are partitioned into contiguous shards and
+// processed by a worker pool sized to cfg.MaxConcurrency (GOMAXPROCS(0) if
+// unset). Within either path, requests are further chunked to the
+// provider's MaxBatchSize before being embedded. Either way, every entry of
+// the returned slice is populated: a cancelled context or a rejected
+// fragment yields {Hash: fragment.ContentHash, Success: false} rather than
+// a nil result.
+func GenerateEmbeddings(ctx context.Context, fragments []types.Fragment, cfg graphql_types.EmbeddingConfig, opts ...Option) (results []*graphql_types.GenerateCodeEmbeddingResult, err error) {
+	results = make([]*graphql_types.GenerateCodeEmbeddingResult, len(fragments))
+	if len(fragments) == 0 {
+		return results, nil
+	}
+
+	defer func() { recordRequestMetrics(cfg.Provider, err) }()
+
+	provider, err := getProvider(cfg.Provider, cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	batch := make([]Request, len(fragments))
 	for i, fragment := range fragments {
 		batch[i] = Request{
@@ -18,39 +48,243 @@ func GenerateEmbeddings(ctx context.Context, fragments []types.Fragment) ([]*gra
 			Fragment: fragment,
 		}
 	}
-	
-	// Primary Fix: Check for context cancellation and initialize failed results
+
 	if ctx.Err() != nil {
-		// Set failed status instead of leaving nil entries
-		for _, request := range batch {
-			results[request.Index] = &graphql_types.GenerateCodeEmbeddingResult{
-				Hash:    request.Fragment.ContentHash,
-				Success: false,
-			}
-		}
+		failAll(batch, results)
 		return results, ctx.Err()
 	}
-	
-	// Process fragments in batches
-	for _, request := range batch {
-		// Simulate batch processing logic (lines 148-149 and 175-176)
+
+	threshold := cfg.ParallelThreshold
+	if threshold <= 0 {
+		threshold = defaultParallelThreshold
+	}
+
+	resolved := resolveOptions(opts)
+
+	if len(batch) > threshold {
+		err = generateEmbeddingsParallel(ctx, provider, cfg, batch, results, resolved)
+	} else {
+		err = generateEmbeddingsSerial(ctx, provider, cfg, batch, results, resolved)
+	}
+	return results, err
+}
+
+// generateEmbeddingsSerial is the sequential path, embedding one chunk at a
+// time, kept for batches too small to be worth spinning up a worker pool for.
+func generateEmbeddingsSerial(ctx context.Context, provider Provider, cfg graphql_types.EmbeddingConfig, batch []Request, results []*graphql_types.GenerateCodeEmbeddingResult, opts embedOptions) error {
+	chunks := chunkRequests(batch, providerBatchSize(provider, cfg, len(batch)))
+
+	for _, chunk := range chunks {
 		if ctx.Err() != nil {
-			// During batch processing, if context is cancelled, ensure proper initialization
-			results[request.Index] = &graphql_types.GenerateCodeEmbeddingResult{
-				Hash:    request.Fragment.ContentHash,
-				Success: false,
-			}
+			failAll(chunk, results)
 			continue
 		}
-		
-		// Normal processing would happen here
-		results[request.Index] = &graphql_types.GenerateCodeEmbeddingResult{
-			Hash:    request.Fragment.ContentHash,
-			Success: true,
+
+		if err := embedChunk(ctx, provider, cfg, chunk, results, opts); err != nil {
+			// Later chunks are never reached; fill their slots too.
+			failUnfilled(batch, results)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generateEmbeddingsParallel partitions batch into contiguous shards and
+// hands one to each of up to cfg.MaxConcurrency workers. Each worker writes
+// only into the results[i] slots for its own shard, so no locking is
+// required. The workers share an errgroup.Group derived from ctx, so the
+// first shard to fail cancels the rest; any slot a cancelled shard didn't
+// get to is still populated with a failure result before returning, so
+// callers never see a nil entry.
+func generateEmbeddingsParallel(ctx context.Context, provider Provider, cfg graphql_types.EmbeddingConfig, batch []Request, results []*graphql_types.GenerateCodeEmbeddingResult, opts embedOptions) error {
+	workers := cfg.MaxConcurrency
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(batch) {
+		workers = len(batch)
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	for _, shard := range shardRequests(batch, workers) {
+		shard := shard
+		g.Go(func() error {
+			for _, chunk := range chunkRequests(shard, providerBatchSize(provider, cfg, len(shard))) {
+				if gctx.Err() != nil {
+					failAll(chunk, results)
+					continue
+				}
+
+				if err := embedChunk(gctx, provider, cfg, chunk, results, opts); err != nil {
+					failUnfilled(chunk, results)
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	err := g.Wait()
+	if err != nil {
+		// A sibling shard may have errored before this shard started; make
+		// sure every slot still got filled.
+		failUnfilled(batch, results)
+	}
+	return err
+}
+
+// embedOutcome carries provider.Embed's return values across the goroutine
+// boundary in embedChunk.
+type embedOutcome struct {
+	results []*graphql_types.GenerateCodeEmbeddingResult
+	err     error
+}
+
+// embedChunk calls provider.Embed for a single chunk and writes each result
+// into its slot of results, normalizing the returned vector first if
+// cfg.Normalize is set. The call runs in its own goroutine so that, unlike
+// a bare ctx.Err() check between chunks, a provider call already in flight
+// can still be abandoned: ctx cancellation and opts.batchTimeout (armed via
+// a deadlineTimer) both race the provider call itself, and whichever fires
+// first wins. On either cancellation path the chunk's results are left for
+// the caller's failUnfilled to populate with Success:false, preserving the
+// no-nil-results invariant; the goroutine itself is allowed to finish
+// writing to resultCh so it doesn't leak.
+func embedChunk(ctx context.Context, provider Provider, cfg graphql_types.EmbeddingConfig, chunk []Request, results []*graphql_types.GenerateCodeEmbeddingResult, opts embedOptions) error {
+	dt := newDeadlineTimer()
+	defer dt.stop()
+	if opts.batchTimeout > 0 {
+		dt.SetBatchDeadline(time.Now().Add(opts.batchTimeout))
+	}
+
+	resultCh := make(chan embedOutcome, 1)
+	go func() {
+		embedded, err := timedEmbed(ctx, provider, chunk)
+		resultCh <- embedOutcome{results: embedded, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-dt.channel():
+		return fmt.Errorf("fragment_embedding: batch of %d requests exceeded its %s deadline", len(chunk), opts.batchTimeout)
+	case outcome := <-resultCh:
+		if outcome.err != nil {
+			return outcome.err
+		}
+
+		for i, request := range chunk {
+			result := outcome.results[i]
+			if result == nil {
+				result = failedResult(request)
+			} else if cfg.Normalize {
+				result.Vector = normalizeVector(result.Vector)
+			}
+			results[request.Index] = result
+		}
+		return nil
+	}
+}
+
+// providerBatchSize resolves how many requests to send to provider.Embed at
+// once: cfg.BatchSize, capped by provider.MaxBatchSize() if that's smaller,
+// falling back to fallback (the whole slice being chunked) if BatchSize
+// isn't set.
+func providerBatchSize(provider Provider, cfg graphql_types.EmbeddingConfig, fallback int) int {
+	size := cfg.BatchSize
+	if size <= 0 {
+		size = fallback
+	}
+	if maxBatch := provider.MaxBatchSize(); maxBatch > 0 && size > maxBatch {
+		size = maxBatch
+	}
+	return size
+}
+
+// normalizeVector scales v to unit length (L2 norm), leaving it untouched
+// if its norm is zero.
+func normalizeVector(v []float32) []float32 {
+	var sumSquares float64
+	for _, x := range v {
+		sumSquares += float64(x) * float64(x)
+	}
+	norm := math.Sqrt(sumSquares)
+	if norm == 0 {
+		return v
+	}
+
+	normalized := make([]float32, len(v))
+	for i, x := range v {
+		normalized[i] = float32(float64(x) / norm)
+	}
+	return normalized
+}
+
+// failedResult builds the placeholder result GenerateEmbeddings returns for
+// a request it didn't get to process.
+func failedResult(request Request) *graphql_types.GenerateCodeEmbeddingResult {
+	return &graphql_types.GenerateCodeEmbeddingResult{
+		Hash:    request.Fragment.ContentHash,
+		Success: false,
+	}
+}
+
+func failAll(batch []Request, results []*graphql_types.GenerateCodeEmbeddingResult) {
+	for _, request := range batch {
+		results[request.Index] = failedResult(request)
+	}
+}
+
+func failUnfilled(batch []Request, results []*graphql_types.GenerateCodeEmbeddingResult) {
+	for _, request := range batch {
+		if results[request.Index] == nil {
+			results[request.Index] = failedResult(request)
+		}
+	}
+}
+
+// shardRequests partitions batch into up to n contiguous, roughly
+// equal-sized shards, preserving order within each shard.
+func shardRequests(batch []Request, n int) [][]Request {
+	if n <= 0 {
+		n = 1
+	}
+	if n > len(batch) {
+		n = len(batch)
+	}
+
+	shards := make([][]Request, 0, n)
+	shardSize := (len(batch) + n - 1) / n
+	for i := 0; i < len(batch); i += shardSize {
+		end := i + shardSize
+		if end > len(batch) {
+			end = len(batch)
+		}
+		shards = append(shards, batch[i:end])
+	}
+	return shards
+}
+
+// chunkRequests splits batch into contiguous groups of at most size,
+// preserving order.
+func chunkRequests(batch []Request, size int) [][]Request {
+	if size <= 0 {
+		size = len(batch)
+	}
+	if size <= 0 {
+		return nil
+	}
+
+	var chunks [][]Request
+	for i := 0; i < len(batch); i += size {
+		end := i + size
+		if end > len(batch) {
+			end = len(batch)
 		}
+		chunks = append(chunks, batch[i:end])
 	}
-	
-	return results, nil
+	return chunks
 }
 
 // Request represents a batch request structure
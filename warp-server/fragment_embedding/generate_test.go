@@ -87,7 +87,7 @@ func TestGenerateEmbeddings_ContextCancellation(t *testing.T) {
 				time.Sleep(2 * time.Millisecond)
 			}
 
-			results, err := GenerateEmbeddings(ctx, tt.fragments)
+			results, err := GenerateEmbeddings(ctx, tt.fragments, graphql_types.EmbeddingConfig{Provider: "mock"})
 
 			// Check error expectation
 			if tt.expectError {
@@ -110,6 +110,81 @@ func TestGenerateEmbeddings_ContextCancellation(t *testing.T) {
 	}
 }
 
+func TestGenerateEmbeddings_ParallelPath(t *testing.T) {
+	fragments := make([]types.Fragment, 250)
+	for i := range fragments {
+		fragments[i] = types.Fragment{
+			Hash:        "fragment",
+			ContentHash: "hash",
+			Content:     "test content",
+			Path:        "src/test.go",
+		}
+	}
+
+	cfg := graphql_types.EmbeddingConfig{Provider: "mock", ParallelThreshold: 100, MaxConcurrency: 4}
+	results, err := GenerateEmbeddings(context.Background(), fragments, cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, len(fragments), len(results))
+
+	for i, result := range results {
+		assert.NotNil(t, result, "Result at index %d should not be nil", i)
+		assert.True(t, result.Success)
+		assert.Equal(t, "hash", result.Hash)
+	}
+}
+
+func TestGenerateEmbeddings_ParallelPathCancellation(t *testing.T) {
+	fragments := make([]types.Fragment, 250)
+	for i := range fragments {
+		fragments[i] = types.Fragment{
+			Hash:        "fragment",
+			ContentHash: "hash",
+			Content:     "test content",
+			Path:        "src/test.go",
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cfg := graphql_types.EmbeddingConfig{Provider: "mock", ParallelThreshold: 100, MaxConcurrency: 4}
+	results, err := GenerateEmbeddings(ctx, fragments, cfg)
+	assert.Error(t, err)
+	assert.Equal(t, len(fragments), len(results))
+
+	for i, result := range results {
+		assert.NotNil(t, result, "Result at index %d should not be nil", i)
+		assert.False(t, result.Success)
+	}
+}
+
+func TestGenerateEmbeddings_UnknownProvider(t *testing.T) {
+	fragments := []types.Fragment{{Hash: "fragment1", ContentHash: "hash1", Content: "test content 1"}}
+
+	results, err := GenerateEmbeddings(context.Background(), fragments, graphql_types.EmbeddingConfig{Provider: "does-not-exist"})
+	assert.Error(t, err)
+	assert.Nil(t, results)
+
+	var unknownProvider *ErrUnknownProvider
+	assert.ErrorAs(t, err, &unknownProvider)
+}
+
+func TestGenerateEmbeddings_Normalize(t *testing.T) {
+	fragments := []types.Fragment{{Hash: "fragment1", ContentHash: "hash1", Content: "test content 1"}}
+
+	cfg := graphql_types.EmbeddingConfig{Provider: "mock", Dimension: 3, Normalize: true}
+	results, err := GenerateEmbeddings(context.Background(), fragments, cfg)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.True(t, results[0].Success)
+
+	var sumSquares float64
+	for _, x := range results[0].Vector {
+		sumSquares += float64(x) * float64(x)
+	}
+	assert.InDelta(t, 1.0, sumSquares, 1e-6)
+}
+
 func TestProcessResults_DefensiveNilCheck(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -157,7 +232,7 @@ func TestProcessResults_DefensiveNilCheck(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// This should not panic even with nil entries
-			hashes := ProcessResults(tt.results)
+			hashes := ProcessResults("mock", tt.results)
 			assert.Equal(t, tt.expectedHashes, hashes)
 		})
 	}
@@ -173,7 +248,7 @@ func TestAppendResults_DefensiveNilCheck(t *testing.T) {
 		{Hash: "hash3", Success: true},
 	}
 
-	finalHashes := AppendResults(initialHashes, results)
+	finalHashes := AppendResults("mock", initialHashes, results)
 	
 	expected := []string{"existing1", "existing2", "hash1", "hash3"}
 	assert.Equal(t, expected, finalHashes)
@@ -0,0 +1,35 @@
+package fragment_embedding
+
+import (
+	"context"
+	"time"
+
+	"github.com/warpdotdev/warp-server/fragment_embedding/metrics"
+	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
+)
+
+// recordRequestMetrics increments metrics.RequestsTotal for a single
+// GenerateEmbeddings call, labeled by provider and whether it returned an
+// error.
+func recordRequestMetrics(provider string, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	metrics.RequestsTotal.WithLabelValues(provider, status).Inc()
+}
+
+// timedEmbed calls provider.Embed, recording metrics.BatchDuration and
+// holding metrics.InflightBatches up for the call's duration, both labeled
+// by provider.Name().
+func timedEmbed(ctx context.Context, provider Provider, chunk []Request) ([]*graphql_types.GenerateCodeEmbeddingResult, error) {
+	name := provider.Name()
+
+	metrics.InflightBatches.WithLabelValues(name).Inc()
+	defer metrics.InflightBatches.WithLabelValues(name).Dec()
+
+	start := time.Now()
+	defer func() { metrics.BatchDuration.WithLabelValues(name).Observe(time.Since(start).Seconds()) }()
+
+	return provider.Embed(ctx, chunk)
+}
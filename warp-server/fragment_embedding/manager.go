@@ -1,31 +1,49 @@
 package fragment_embedding
 
 import (
+	"github.com/warpdotdev/warp-server/fragment_embedding/metrics"
 	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
 )
 
-// ProcessResults processes embedding results with defensive nil checks
-func ProcessResults(results []*graphql_types.GenerateCodeEmbeddingResult) []string {
+// ProcessResults processes embedding results with defensive nil checks,
+// recording metrics.FragmentsTotal for each one (provider identifies which
+// Provider produced results, for labeling).
+func ProcessResults(provider string, results []*graphql_types.GenerateCodeEmbeddingResult) []string {
 	successfulHashes := make([]string, 0)
-	
+
 	for _, result := range results {
 		// Defensive check to avoid nil pointer dereference
-		if result != nil && result.Success {
+		if result == nil {
+			metrics.FragmentsTotal.WithLabelValues(provider, "skipped").Inc()
+			continue
+		}
+		if result.Success {
+			metrics.FragmentsTotal.WithLabelValues(provider, "success").Inc()
 			successfulHashes = append(successfulHashes, result.Hash)
+		} else {
+			metrics.FragmentsTotal.WithLabelValues(provider, "failure").Inc()
 		}
 	}
-	
+
 	return successfulHashes
 }
 
-// AppendResults safely appends embedding results to a slice
-func AppendResults(successfulHashes []string, results []*graphql_types.GenerateCodeEmbeddingResult) []string {
+// AppendResults safely appends embedding results to a slice, recording
+// metrics.FragmentsTotal the same way ProcessResults does.
+func AppendResults(provider string, successfulHashes []string, results []*graphql_types.GenerateCodeEmbeddingResult) []string {
 	for _, result := range results {
-		// Defensive check to avoid nil pointer dereference  
-		if result != nil && result.Success {
+		// Defensive check to avoid nil pointer dereference
+		if result == nil {
+			metrics.FragmentsTotal.WithLabelValues(provider, "skipped").Inc()
+			continue
+		}
+		if result.Success {
+			metrics.FragmentsTotal.WithLabelValues(provider, "success").Inc()
 			successfulHashes = append(successfulHashes, result.Hash)
+		} else {
+			metrics.FragmentsTotal.WithLabelValues(provider, "failure").Inc()
 		}
 	}
-	
+
 	return successfulHashes
 }
@@ -0,0 +1,62 @@
+// Package metrics instruments fragment_embedding with Prometheus
+// collectors, so a pipeline that otherwise only shows up in logs (how many
+// requests and fragments flow through, how long each provider batch call
+// takes, how many are in flight) is visible on a dashboard instead.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry is the prometheus.Registerer/Gatherer the collectors below are
+// registered against. It is a registry of this package's own rather than
+// prometheus.DefaultRegisterer, so its collectors don't collide with other
+// packages' collectors. The collectors are registered with it once, in init;
+// assigning a different registry to Registry afterwards does not register
+// them there, so Handler would then serve none of them.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// RequestsTotal counts GenerateEmbeddings calls, by provider and
+	// status ("ok" or "error").
+	RequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "warp_embedding_requests_total",
+		Help: "Total GenerateEmbeddings calls, by provider and status.",
+	}, []string{"provider", "status"})
+
+	// FragmentsTotal counts individual fragments processed by
+	// ProcessResults/AppendResults, by provider and outcome.
+	FragmentsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "warp_embedding_fragments_total",
+		Help: "Total fragments processed, by provider and outcome (success, failure, or skipped).",
+	}, []string{"provider", "outcome"})
+
+	// BatchDuration measures how long a single provider batch embedding
+	// call takes.
+	BatchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "warp_embedding_batch_duration_seconds",
+		Help:    "Duration of a single provider batch embedding call.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"provider"})
+
+	// InflightBatches tracks how many provider batch embedding calls are
+	// currently in flight.
+	InflightBatches = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "warp_embedding_inflight_batches",
+		Help: "Number of provider batch embedding calls currently in flight.",
+	}, []string{"provider"})
+)
+
+func init() {
+	Registry.MustRegister(RequestsTotal, FragmentsTotal, BatchDuration, InflightBatches)
+}
+
+// Handler returns an http.Handler serving Registry's collected metrics in
+// the Prometheus exposition format, for the parent server to mount at
+// /metrics.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFragmentsTotal_IncrementsByLabel(t *testing.T) {
+	FragmentsTotal.Reset()
+
+	FragmentsTotal.WithLabelValues("mock", "success").Inc()
+	FragmentsTotal.WithLabelValues("mock", "success").Inc()
+	FragmentsTotal.WithLabelValues("mock", "failure").Inc()
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(FragmentsTotal.WithLabelValues("mock", "success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(FragmentsTotal.WithLabelValues("mock", "failure")))
+}
+
+func TestHandler_ServesRegisteredCollectors(t *testing.T) {
+	RequestsTotal.Reset()
+	RequestsTotal.WithLabelValues("mock", "ok").Inc()
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+
+	Handler().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "warp_embedding_requests_total")
+}
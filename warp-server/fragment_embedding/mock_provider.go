@@ -0,0 +1,44 @@
+package fragment_embedding
+
+import (
+	"context"
+
+	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
+)
+
+// mockProvider is a deterministic Provider with no external dependencies,
+// registered as "mock" so tests (and local development) can exercise
+// GenerateEmbeddings without a real backend.
+type mockProvider struct {
+	dimension int
+}
+
+func newMockProvider(cfg graphql_types.EmbeddingConfig) (Provider, error) {
+	dimension := cfg.Dimension
+	if dimension <= 0 {
+		dimension = 1
+	}
+	return &mockProvider{dimension: dimension}, nil
+}
+
+func (p *mockProvider) Name() string      { return "mock" }
+func (p *mockProvider) Dimension() int    { return p.dimension }
+func (p *mockProvider) MaxBatchSize() int { return 0 }
+
+// Embed implements Provider, returning a fixed, nonzero vector per request
+// derived from its position in the batch.
+func (p *mockProvider) Embed(ctx context.Context, batch []Request) ([]*graphql_types.GenerateCodeEmbeddingResult, error) {
+	results := make([]*graphql_types.GenerateCodeEmbeddingResult, len(batch))
+	for i, request := range batch {
+		vector := make([]float32, p.dimension)
+		for j := range vector {
+			vector[j] = float32(i*p.dimension + j + 1)
+		}
+		results[i] = &graphql_types.GenerateCodeEmbeddingResult{
+			Hash:    request.Fragment.ContentHash,
+			Success: true,
+			Vector:  vector,
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,76 @@
+package fragment_embedding
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
+)
+
+// ONNXRunner is implemented by whatever local inference runtime is wired up
+// (e.g. a sentence-transformers model loaded through an ONNX Runtime
+// binding). It's injected rather than linked directly so this package
+// doesn't need a cgo dependency to build.
+type ONNXRunner interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// onnxRunner is the process-wide ONNXRunner used by the "onnx" provider.
+// There's no default: callers must set it with SetONNXRunner before any
+// fragment carries Provider: "onnx". It's an atomic.Pointer rather than a
+// bare var because SetONNXRunner can race with in-flight Embed calls (e.g. a
+// caller re-registering a runner while requests are still being processed
+// under GenerateEmbeddings' parallel path).
+var onnxRunner atomic.Pointer[ONNXRunner]
+
+// SetONNXRunner configures the runtime the "onnx" provider delegates to.
+func SetONNXRunner(runner ONNXRunner) {
+	onnxRunner.Store(&runner)
+}
+
+// onnxProvider adapts the package-level ONNXRunner to Provider.
+type onnxProvider struct {
+	dimension int
+}
+
+func newONNXProvider(cfg graphql_types.EmbeddingConfig) (Provider, error) {
+	return &onnxProvider{dimension: cfg.Dimension}, nil
+}
+
+func (p *onnxProvider) Name() string      { return "onnx" }
+func (p *onnxProvider) Dimension() int    { return p.dimension }
+func (p *onnxProvider) MaxBatchSize() int { return 0 }
+
+// Embed implements Provider.
+func (p *onnxProvider) Embed(ctx context.Context, batch []Request) ([]*graphql_types.GenerateCodeEmbeddingResult, error) {
+	runner := onnxRunner.Load()
+	if runner == nil {
+		return nil, errors.New("onnx provider: no ONNXRunner configured")
+	}
+
+	texts := make([]string, len(batch))
+	for i, request := range batch {
+		texts[i] = request.Fragment.Content
+	}
+
+	vectors, err := (*runner).Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*graphql_types.GenerateCodeEmbeddingResult, len(batch))
+	for i, request := range batch {
+		if i >= len(vectors) || vectors[i] == nil {
+			results[i] = failedResult(request)
+			continue
+		}
+		results[i] = &graphql_types.GenerateCodeEmbeddingResult{
+			Hash:    request.Fragment.ContentHash,
+			Success: true,
+			Vector:  vectors[i],
+		}
+	}
+
+	return results, nil
+}
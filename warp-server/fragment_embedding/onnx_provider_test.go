@@ -0,0 +1,76 @@
+package fragment_embedding
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/warpdotdev/warp-server/model/types"
+	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
+)
+
+type fakeONNXRunner struct {
+	vectors [][]float32
+}
+
+func (r fakeONNXRunner) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return r.vectors, nil
+}
+
+func TestOnnxProvider_Embed_UsesConfiguredRunner(t *testing.T) {
+	SetONNXRunner(fakeONNXRunner{vectors: [][]float32{{1, 2, 3}}})
+	t.Cleanup(func() { onnxRunner.Store(nil) })
+
+	p, err := newONNXProvider(graphql_types.EmbeddingConfig{Dimension: 3})
+	if err != nil {
+		t.Fatalf("newONNXProvider() error = %v", err)
+	}
+
+	results, err := p.Embed(context.Background(), []Request{{Fragment: types.Fragment{ContentHash: "h1"}}})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("Embed() = %+v, want a single successful result", results)
+	}
+}
+
+func TestOnnxProvider_Embed_ErrorsWithNoRunnerConfigured(t *testing.T) {
+	onnxRunner.Store(nil)
+
+	p, err := newONNXProvider(graphql_types.EmbeddingConfig{Dimension: 3})
+	if err != nil {
+		t.Fatalf("newONNXProvider() error = %v", err)
+	}
+
+	if _, err := p.Embed(context.Background(), []Request{{Fragment: types.Fragment{ContentHash: "h1"}}}); err == nil {
+		t.Fatal("Embed() expected an error when no ONNXRunner is configured")
+	}
+}
+
+// TestSetONNXRunner_ConcurrentWithEmbed exercises SetONNXRunner racing with
+// Embed (e.g. two repos re-registering different runners while requests are
+// still in flight) under `go test -race`.
+func TestSetONNXRunner_ConcurrentWithEmbed(t *testing.T) {
+	SetONNXRunner(fakeONNXRunner{vectors: [][]float32{{1}}})
+	t.Cleanup(func() { onnxRunner.Store(nil) })
+
+	p, err := newONNXProvider(graphql_types.EmbeddingConfig{Dimension: 3})
+	if err != nil {
+		t.Fatalf("newONNXProvider() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetONNXRunner(fakeONNXRunner{vectors: [][]float32{{1}}})
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = p.Embed(context.Background(), []Request{{Fragment: types.Fragment{ContentHash: "h1"}}})
+		}()
+	}
+	wg.Wait()
+}
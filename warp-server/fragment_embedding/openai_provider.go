@@ -0,0 +1,116 @@
+package fragment_embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
+	"github.com/warpdotdev/warp-server/pkg/retry"
+)
+
+// openAIProvider calls OpenAI's /v1/embeddings endpoint.
+type openAIProvider struct {
+	apiKey     string
+	baseURL    string
+	model      string
+	dimension  int
+	httpClient *http.Client
+	maxRetries int
+}
+
+// openAIMaxBatchSize mirrors OpenAI's documented limit on inputs per
+// embeddings request.
+const openAIMaxBatchSize = 2048
+
+func newOpenAIProvider(cfg graphql_types.EmbeddingConfig) (Provider, error) {
+	return &openAIProvider{
+		apiKey:     os.Getenv("OPENAI_API_KEY"),
+		baseURL:    "https://api.openai.com/v1",
+		model:      cfg.Model,
+		dimension:  cfg.Dimension,
+		httpClient: http.DefaultClient,
+		maxRetries: 3,
+	}, nil
+}
+
+func (p *openAIProvider) Name() string      { return "openai" }
+func (p *openAIProvider) Dimension() int    { return p.dimension }
+func (p *openAIProvider) MaxBatchSize() int { return openAIMaxBatchSize }
+
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+		Index     int       `json:"index"`
+	} `json:"data"`
+}
+
+// Embed implements Provider.
+func (p *openAIProvider) Embed(ctx context.Context, batch []Request) ([]*graphql_types.GenerateCodeEmbeddingResult, error) {
+	texts := make([]string, len(batch))
+	for i, request := range batch {
+		texts[i] = request.Fragment.Content
+	}
+
+	var results []*graphql_types.GenerateCodeEmbeddingResult
+	err := retry.Do(ctx, p.maxRetries, func() error {
+		payload, err := json.Marshal(openAIEmbeddingRequest{Model: p.model, Input: texts})
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/embeddings", bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+		resp, err := p.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("openai embed request failed with status %d", resp.StatusCode)
+		}
+
+		var parsed openAIEmbeddingResponse
+		if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+			return err
+		}
+
+		batchResults := make([]*graphql_types.GenerateCodeEmbeddingResult, len(batch))
+		for _, item := range parsed.Data {
+			if item.Index < 0 || item.Index >= len(batch) {
+				continue
+			}
+			batchResults[item.Index] = &graphql_types.GenerateCodeEmbeddingResult{
+				Hash:    batch[item.Index].Fragment.ContentHash,
+				Success: true,
+				Vector:  item.Embedding,
+			}
+		}
+		for i, result := range batchResults {
+			if result == nil {
+				batchResults[i] = failedResult(batch[i])
+			}
+		}
+		results = batchResults
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
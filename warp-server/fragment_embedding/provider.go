@@ -0,0 +1,70 @@
+package fragment_embedding
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
+)
+
+// Provider turns a batch of fragment requests into embedding results using
+// a specific backend (OpenAI, Cohere, a local ONNX runtime, ...). New
+// backends are added by implementing this interface and registering a
+// factory with Register, without touching GenerateEmbeddings.
+type Provider interface {
+	// Embed computes a result for every request in batch, in the same
+	// order. The returned slice always has len(batch) entries.
+	Embed(ctx context.Context, batch []Request) ([]*graphql_types.GenerateCodeEmbeddingResult, error)
+	Name() string
+	Dimension() int
+	// MaxBatchSize caps how many requests GenerateEmbeddings will send to
+	// Embed in a single call. Zero or negative means no provider-side cap.
+	MaxBatchSize() int
+}
+
+// ProviderFactory builds a Provider configured from cfg (model, API key
+// lookup, dimension, ...).
+type ProviderFactory func(cfg graphql_types.EmbeddingConfig) (Provider, error)
+
+var (
+	providerMu sync.RWMutex
+	providers  = map[string]ProviderFactory{}
+)
+
+// Register associates a provider name (as set via EmbeddingConfig.Provider)
+// with a ProviderFactory. Calling Register with a name that is already
+// registered replaces the previous factory.
+func Register(name string, factory ProviderFactory) {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	providers[name] = factory
+}
+
+// getProvider builds the Provider registered under name, if any.
+func getProvider(name string, cfg graphql_types.EmbeddingConfig) (Provider, error) {
+	providerMu.RLock()
+	factory, ok := providers[name]
+	providerMu.RUnlock()
+	if !ok {
+		return nil, &ErrUnknownProvider{Provider: name}
+	}
+	return factory(cfg)
+}
+
+// ErrUnknownProvider is returned by GenerateEmbeddings when
+// EmbeddingConfig.Provider does not match any registered Provider.
+type ErrUnknownProvider struct {
+	Provider string
+}
+
+func (e *ErrUnknownProvider) Error() string {
+	return fmt.Sprintf("fragment_embedding: unknown provider %q", e.Provider)
+}
+
+func init() {
+	Register("openai", newOpenAIProvider)
+	Register("cohere", newCohereProvider)
+	Register("onnx", newONNXProvider)
+	Register("mock", newMockProvider)
+}
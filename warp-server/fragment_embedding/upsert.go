@@ -0,0 +1,135 @@
+package fragment_embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/warpdotdev/warp-server/model/types"
+	"github.com/warpdotdev/warp-server/model/types/ai"
+	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
+)
+
+// EmbeddingStore is the persistence layer UpsertIfChanged writes through.
+// Entries are addressed by Fragment.ContentHash, so identical content
+// never needs to be re-embedded regardless of which fragment it came from.
+type EmbeddingStore interface {
+	// ExistingHashes reports, for each of contentHashes, whether a fragment
+	// with that exact content is already embedded and stored.
+	ExistingHashes(ctx context.Context, contentHashes []string) ([]ai.ContentHashJbool, error)
+
+	// Write stores result under contentHash. It returns ErrWriteConflict
+	// if another writer updated contentHash after this call's caller last
+	// observed it via ExistingHashes; UpsertIfChanged re-checks
+	// ExistingHashes before deciding whether that conflict still needs a
+	// retry.
+	Write(ctx context.Context, contentHash string, result *graphql_types.GenerateCodeEmbeddingResult) error
+}
+
+// ErrWriteConflict is returned by EmbeddingStore.Write when another writer
+// updated contentHash after the caller last observed it.
+type ErrWriteConflict struct {
+	ContentHash string
+}
+
+func (e *ErrWriteConflict) Error() string {
+	return fmt.Sprintf("fragment_embedding: write conflict for content hash %q", e.ContentHash)
+}
+
+// UpsertIfChanged generates and stores embeddings for fragments whose
+// content isn't already in store, borrowing etcd3 GuaranteedUpdate's
+// read-compute-retry-only-if-changed pattern to avoid paying provider cost
+// for content that hasn't moved. It skips pending's provider calls
+// entirely for fragments store already has, and re-checks ExistingHashes
+// on a write conflict instead of blindly retrying: if the conflicting
+// writer already landed the same content hash, the fragment is skipped
+// rather than regenerated; if the hash is still missing, the write is
+// retried once with the embedding already computed.
+func UpsertIfChanged(ctx context.Context, fragments []types.Fragment, cfg graphql_types.EmbeddingConfig, store EmbeddingStore) (written, skipped []string, err error) {
+	if len(fragments) == 0 {
+		return nil, nil, nil
+	}
+
+	contentHashes := make([]string, len(fragments))
+	for i, fragment := range fragments {
+		contentHashes[i] = fragment.ContentHash
+	}
+
+	existing, err := store.ExistingHashes(ctx, contentHashes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	existsByHash := make(map[string]bool, len(existing))
+	for _, e := range existing {
+		if e.Exists {
+			existsByHash[e.Hash] = true
+		}
+	}
+
+	pending := make([]types.Fragment, 0, len(fragments))
+	for _, fragment := range fragments {
+		if existsByHash[fragment.ContentHash] {
+			skipped = append(skipped, fragment.ContentHash)
+		} else {
+			pending = append(pending, fragment)
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil, skipped, nil
+	}
+
+	results, genErr := GenerateEmbeddings(ctx, pending, cfg)
+	if genErr != nil {
+		return nil, skipped, genErr
+	}
+
+	for i, fragment := range pending {
+		result := results[i]
+		if result == nil || !result.Success {
+			continue
+		}
+
+		writeErr := store.Write(ctx, fragment.ContentHash, result)
+
+		var conflict *ErrWriteConflict
+		if errors.As(writeErr, &conflict) {
+			stillMissing, recheckErr := contentHashMissing(ctx, store, fragment.ContentHash)
+			if recheckErr != nil {
+				err = recheckErr
+				continue
+			}
+			if !stillMissing {
+				// A concurrent writer already stored this exact content;
+				// no need to regenerate it ourselves.
+				skipped = append(skipped, fragment.ContentHash)
+				continue
+			}
+			writeErr = store.Write(ctx, fragment.ContentHash, result)
+		}
+		if writeErr != nil {
+			err = writeErr
+			continue
+		}
+
+		written = append(written, fragment.ContentHash)
+	}
+
+	return written, skipped, err
+}
+
+// contentHashMissing reports whether store still has no entry for
+// contentHash, used to decide whether a write conflict needs surfacing.
+func contentHashMissing(ctx context.Context, store EmbeddingStore, contentHash string) (bool, error) {
+	existing, err := store.ExistingHashes(ctx, []string{contentHash})
+	if err != nil {
+		return false, err
+	}
+	for _, e := range existing {
+		if e.Hash == contentHash {
+			return !e.Exists, nil
+		}
+	}
+	return true, nil
+}
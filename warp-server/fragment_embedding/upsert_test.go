@@ -0,0 +1,95 @@
+package fragment_embedding
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/warpdotdev/warp-server/model/types"
+	"github.com/warpdotdev/warp-server/model/types/ai"
+	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
+)
+
+// fakeStore is a minimal, in-memory EmbeddingStore for exercising
+// UpsertIfChanged without a real persistence layer. Setting conflictOnce
+// makes the next Write for that content hash return ErrWriteConflict.
+type fakeStore struct {
+	mu           sync.Mutex
+	entries      map[string]*graphql_types.GenerateCodeEmbeddingResult
+	conflictOnce map[string]bool
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		entries:      make(map[string]*graphql_types.GenerateCodeEmbeddingResult),
+		conflictOnce: make(map[string]bool),
+	}
+}
+
+func (s *fakeStore) ExistingHashes(ctx context.Context, contentHashes []string) ([]ai.ContentHashJbool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]ai.ContentHashJbool, len(contentHashes))
+	for i, hash := range contentHashes {
+		_, exists := s.entries[hash]
+		result[i] = ai.ContentHashJbool{Hash: hash, Exists: exists}
+	}
+	return result, nil
+}
+
+func (s *fakeStore) Write(ctx context.Context, contentHash string, result *graphql_types.GenerateCodeEmbeddingResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conflictOnce[contentHash] {
+		delete(s.conflictOnce, contentHash)
+		// Simulate a racing writer landing the same content hash first.
+		s.entries[contentHash] = result
+		return &ErrWriteConflict{ContentHash: contentHash}
+	}
+	s.entries[contentHash] = result
+	return nil
+}
+
+func mockConfig() graphql_types.EmbeddingConfig {
+	return graphql_types.EmbeddingConfig{Provider: "mock", Dimension: 2}
+}
+
+func TestUpsertIfChanged_SkipsAlreadyStoredContent(t *testing.T) {
+	store := newFakeStore()
+	store.entries["hash1"] = &graphql_types.GenerateCodeEmbeddingResult{Hash: "hash1", Success: true}
+
+	fragments := []types.Fragment{
+		{Hash: "a", ContentHash: "hash1", Content: "unchanged"},
+		{Hash: "b", ContentHash: "hash2", Content: "new"},
+	}
+
+	written, skipped, err := UpsertIfChanged(context.Background(), fragments, mockConfig(), store)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hash2"}, written)
+	assert.Equal(t, []string{"hash1"}, skipped)
+}
+
+func TestUpsertIfChanged_ConflictAlreadyWrittenIsSkipped(t *testing.T) {
+	store := newFakeStore()
+	store.conflictOnce["hash1"] = true
+
+	fragments := []types.Fragment{{Hash: "a", ContentHash: "hash1", Content: "raced"}}
+
+	written, skipped, err := UpsertIfChanged(context.Background(), fragments, mockConfig(), store)
+
+	assert.NoError(t, err)
+	assert.Empty(t, written)
+	assert.Equal(t, []string{"hash1"}, skipped)
+}
+
+func TestUpsertIfChanged_NoFragments(t *testing.T) {
+	written, skipped, err := UpsertIfChanged(context.Background(), nil, mockConfig(), newFakeStore())
+
+	assert.NoError(t, err)
+	assert.Nil(t, written)
+	assert.Nil(t, skipped)
+}
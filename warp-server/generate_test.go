@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,23 +24,55 @@ func (m *MockStore) CheckFragmentEmbeddingsExist(ctx context.Context, embeddingC
 	return args.Get(0).([]ai.ContentHashJbool), args.Error(1)
 }
 
-func (m *MockStore) StoreFragmentEmbeddings(ctx context.Context, embeddingConfig graphql_types.EmbeddingConfig, repoMetadata types.RepoMetadata, graphql_types types.GraphQLTypes) error {
-	args := m.Called(ctx, embeddingConfig, repoMetadata, graphql_types)
+func (m *MockStore) GuardedUpdate(ctx context.Context, key string, tryUpdate func(current *ai.Embedding) (*ai.Embedding, error)) error {
+	args := m.Called(ctx, key, tryUpdate)
 	return args.Error(0)
 }
 
+// fakeBackend is a deterministic EmbeddingBackend stand-in so tests don't
+// need a real provider or namespaced model name.
+type fakeBackend struct{}
+
+func (fakeBackend) Embed(ctx context.Context, model string, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = []float32{float32(i)}
+	}
+	return vectors, nil
+}
+
+// partialFailBackend rejects any batch containing a text with failSubstr,
+// so tests can exercise a single batch erroring out among several.
+type partialFailBackend struct {
+	failSubstr string
+}
+
+func (b partialFailBackend) Embed(ctx context.Context, model string, texts []string) ([][]float32, error) {
+	for _, text := range texts {
+		if strings.Contains(text, b.failSubstr) {
+			return nil, errors.New("batch rejected")
+		}
+	}
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = []float32{float32(i)}
+	}
+	return vectors, nil
+}
+
 func TestGenerateEmbeddings(t *testing.T) {
 	ctx := context.Background()
 	
 	tests := []struct {
-		name           string
+		name            string
 		embeddingConfig graphql_types.EmbeddingConfig
-		repoMetadata   types.RepoMetadata
-		fragments      []types.Fragment
-		userUID        string
-		setupMock      func(*MockStore)
-		expectError    bool
-		expectedCount  int
+		repoMetadata    types.RepoMetadata
+		fragments       []types.Fragment
+		userUID         string
+		setupMock       func(*MockStore)
+		expectError     bool
+		expectNilResult bool
+		expectedCount   int
 	}{
 		{
 			name: "successful_generation_with_new_fragments",
@@ -74,8 +107,8 @@ func TestGenerateEmbeddings(t *testing.T) {
 					{Hash: "fragment2", Exists: false},
 				}, nil)
 				
-				// Mock successful storage
-				mockStore.On("StoreFragmentEmbeddings", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				// Mock successful per-hash guarded store
+				mockStore.On("GuardedUpdate", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 			},
 			expectError:   false,
 			expectedCount: 2,
@@ -113,8 +146,8 @@ func TestGenerateEmbeddings(t *testing.T) {
 					{Hash: "fragment2", Exists: false},
 				}, nil)
 				
-				// Mock successful storage for only the new fragment
-				mockStore.On("StoreFragmentEmbeddings", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				// Mock successful guarded store for only the new fragment
+				mockStore.On("GuardedUpdate", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 			},
 			expectError:   false,
 			expectedCount: 1, // Only one new embedding generated
@@ -141,8 +174,9 @@ func TestGenerateEmbeddings(t *testing.T) {
 			setupMock: func(mockStore *MockStore) {
 				mockStore.On("CheckFragmentEmbeddingsExist", mock.Anything, mock.Anything, mock.Anything, mock.Anything, "user123").Return([]ai.ContentHashJbool{}, errors.New("database error"))
 			},
-			expectError:   true,
-			expectedCount: 0,
+			expectError:     true,
+			expectNilResult: true,
+			expectedCount:   0,
 		},
 		{
 			name: "error_storing_embeddings",
@@ -168,10 +202,12 @@ func TestGenerateEmbeddings(t *testing.T) {
 					{Hash: "fragment1", Exists: false},
 				}, nil)
 				
-				mockStore.On("StoreFragmentEmbeddings", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("storage error"))
+				mockStore.On("GuardedUpdate", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("storage error"))
 			},
 			expectError:   true,
-			expectedCount: 0,
+			// The embedding was computed successfully before the storage error, so
+			// it's still returned rather than dropped.
+			expectedCount: 1,
 		},
 		{
 			name: "empty_fragments_list",
@@ -200,7 +236,7 @@ func TestGenerateEmbeddings(t *testing.T) {
 			tt.setupMock(mockStore)
 
 			// Create the generator with the mock store
-			generator := caching_generator.New(mockStore)
+			generator := caching_generator.NewWithBackend(mockStore, fakeBackend{})
 
 			// Call GenerateEmbeddings
 			result, err := generator.GenerateEmbeddings(ctx, tt.embeddingConfig, tt.repoMetadata, tt.fragments, tt.userUID)
@@ -208,7 +244,12 @@ func TestGenerateEmbeddings(t *testing.T) {
 			// Assert error expectation
 			if tt.expectError {
 				assert.Error(t, err)
-				assert.Nil(t, result)
+				if tt.expectNilResult {
+					assert.Nil(t, result)
+				} else if assert.NotNil(t, result) {
+					assert.False(t, result.Success)
+					assert.Equal(t, tt.expectedCount, len(result.Embeddings))
+				}
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, result)
@@ -220,3 +261,38 @@ func TestGenerateEmbeddings(t *testing.T) {
 		})
 	}
 }
+
+// TestGenerateEmbeddings_PartialBatchFailureKeepsSuccessfulEmbeddings ensures
+// one rejected batch doesn't throw away embeddings already computed by
+// sibling batches.
+func TestGenerateEmbeddings_PartialBatchFailureKeepsSuccessfulEmbeddings(t *testing.T) {
+	ctx := context.Background()
+
+	mockStore := &MockStore{}
+	mockStore.On("CheckFragmentEmbeddingsExist", mock.Anything, mock.Anything, mock.Anything, mock.Anything, "user123").Return([]ai.ContentHashJbool{
+		{Hash: "fragment1", Exists: false},
+		{Hash: "fragment2", Exists: false},
+	}, nil)
+	mockStore.On("GuardedUpdate", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	generator := caching_generator.NewWithBackend(mockStore, partialFailBackend{failSubstr: "bad"})
+
+	embeddingConfig := graphql_types.EmbeddingConfig{Model: "text-embedding-ada-002", BatchSize: 1}
+	repoMetadata := types.RepoMetadata{RepoName: "test-repo", Branch: "main", CommitSHA: "abc123"}
+	fragments := []types.Fragment{
+		{Hash: "fragment1", Content: "good content", Path: "src/test1.go"},
+		{Hash: "fragment2", Content: "bad content", Path: "src/test2.go"},
+	}
+
+	result, err := generator.GenerateEmbeddings(ctx, embeddingConfig, repoMetadata, fragments, "user123")
+
+	assert.Error(t, err)
+	if assert.NotNil(t, result) {
+		assert.False(t, result.Success)
+		if assert.Len(t, result.Embeddings, 1) {
+			assert.Equal(t, "fragment1", result.Embeddings[0].Hash)
+		}
+	}
+
+	mockStore.AssertExpectations(t)
+}
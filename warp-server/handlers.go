@@ -1,14 +1,13 @@
 package main
 
 import (
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/warpdotdev/warp-server/apidef/importer"
 )
 
 // APIResponse represents a standard API response
@@ -19,6 +18,31 @@ type APIResponse struct {
 	Error   string      `json:"error,omitempty"`
 }
 
+// Command is a row from the `commands` table, as returned by
+// RecentCommandsHandler.
+type Command struct {
+	Command     string     `json:"command"`
+	ExitCode    *int       `json:"exit_code"`
+	StartTs     *time.Time `json:"start_ts"`
+	CompletedTs *time.Time `json:"completed_ts"`
+	Pwd         *string    `json:"pwd"`
+	Shell       *string    `json:"shell"`
+	Username    *string    `json:"username"`
+	Hostname    *string    `json:"hostname"`
+}
+
+// AIQuery is a row from the `ai_queries` table, as returned by
+// RecentAIQueriesHandler.
+type AIQuery struct {
+	ExchangeId       string     `json:"exchange_id"`
+	ConversationId   string     `json:"conversation_id"`
+	StartTs          *time.Time `json:"start_ts"`
+	Input            string     `json:"input"`
+	WorkingDirectory *string    `json:"working_directory"`
+	OutputStatus     string     `json:"output_status"`
+	ModelId          string     `json:"model_id"`
+}
+
 // HomeHandler handles the root endpoint
 func HomeHandler(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/" {
@@ -81,18 +105,15 @@ func NotFoundHandler(w http.ResponseWriter, r *http.Request) {
 // DatabaseStatsHandler provides database statistics
 func DatabaseStatsHandler(w http.ResponseWriter, r *http.Request) {
 	config := LoadConfig()
-	dbPath := config.DatabasePath
-	
-	db, err := sql.Open("sqlite3", dbPath)
-	if err != nil {
-		response := APIResponse{
+	if config.DB == nil {
+		writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
 			Success: false,
-			Error:   fmt.Sprintf("Failed to open database: %v", err),
-		}
-		writeJSONResponse(w, http.StatusInternalServerError, response)
+			Error:   "database pool is not available",
+		})
 		return
 	}
-	defer db.Close()
+
+	ctx := r.Context()
 
 	// Get table counts
 	tables := []string{
@@ -103,7 +124,7 @@ func DatabaseStatsHandler(w http.ResponseWriter, r *http.Request) {
 	tableCounts := make(map[string]int)
 	for _, table := range tables {
 		var count int
-		err := db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", table)).Scan(&count)
+		err := config.DB.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", table)).Scan(&count)
 		if err != nil {
 			tableCounts[table] = -1 // Mark as error
 		} else {
@@ -113,14 +134,14 @@ func DatabaseStatsHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get database size (if possible)
 	var pageCount, pageSize int
-	db.QueryRow("PRAGMA page_count").Scan(&pageCount)
-	db.QueryRow("PRAGMA page_size").Scan(&pageSize)
+	config.DB.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pageCount)
+	config.DB.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize)
 	dbSize := pageCount * pageSize
 
 	response := APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
-			"database_path": dbPath,
+			"database_path": config.DatabasePath,
 			"database_size_bytes": dbSize,
 			"table_counts": tableCounts,
 			"timestamp": time.Now().Format(time.RFC3339),
@@ -133,7 +154,13 @@ func DatabaseStatsHandler(w http.ResponseWriter, r *http.Request) {
 // RecentCommandsHandler returns recent commands from the database
 func RecentCommandsHandler(w http.ResponseWriter, r *http.Request) {
 	config := LoadConfig()
-	dbPath := config.DatabasePath
+	if config.DB == nil {
+		writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "database pool is not available",
+		})
+		return
+	}
 
 	limitStr := r.URL.Query().Get("limit")
 	limit := 10 // default
@@ -143,25 +170,14 @@ func RecentCommandsHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	db, err := sql.Open("sqlite3", dbPath)
-	if err != nil {
-		response := APIResponse{
-			Success: false,
-			Error:   fmt.Sprintf("Failed to open database: %v", err),
-		}
-		writeJSONResponse(w, http.StatusInternalServerError, response)
-		return
-	}
-	defer db.Close()
-
 	query := `
-		SELECT command, exit_code, start_ts, completed_ts, pwd, shell, username, hostname 
-		FROM commands 
-		ORDER BY start_ts DESC 
+		SELECT command, exit_code, start_ts, completed_ts, pwd, shell, username, hostname
+		FROM commands
+		ORDER BY start_ts DESC
 		LIMIT ?
 	`
 
-	rows, err := db.Query(query, limit)
+	rows, err := config.DB.QueryContext(r.Context(), query, limit)
 	if err != nil {
 		response := APIResponse{
 			Success: false,
@@ -172,17 +188,6 @@ func RecentCommandsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer rows.Close()
 
-	type Command struct {
-		Command     string     `json:"command"`
-		ExitCode    *int       `json:"exit_code"`
-		StartTs     *time.Time `json:"start_ts"`
-		CompletedTs *time.Time `json:"completed_ts"`
-		Pwd         *string    `json:"pwd"`
-		Shell       *string    `json:"shell"`
-		Username    *string    `json:"username"`
-		Hostname    *string    `json:"hostname"`
-	}
-
 	var commands []Command
 	for rows.Next() {
 		var cmd Command
@@ -193,6 +198,8 @@ func RecentCommandsHandler(w http.ResponseWriter, r *http.Request) {
 		commands = append(commands, cmd)
 	}
 
+	sortCommands(commands, parseSortSpec(r.URL.Query().Get("sort")))
+
 	response := APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
@@ -208,7 +215,13 @@ func RecentCommandsHandler(w http.ResponseWriter, r *http.Request) {
 // RecentAIQueriesHandler returns recent AI queries
 func RecentAIQueriesHandler(w http.ResponseWriter, r *http.Request) {
 	config := LoadConfig()
-	dbPath := config.DatabasePath
+	if config.DB == nil {
+		writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "database pool is not available",
+		})
+		return
+	}
 
 	limitStr := r.URL.Query().Get("limit")
 	limit := 10
@@ -218,25 +231,14 @@ func RecentAIQueriesHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	db, err := sql.Open("sqlite3", dbPath)
-	if err != nil {
-		response := APIResponse{
-			Success: false,
-			Error:   fmt.Sprintf("Failed to open database: %v", err),
-		}
-		writeJSONResponse(w, http.StatusInternalServerError, response)
-		return
-	}
-	defer db.Close()
-
 	query := `
 		SELECT exchange_id, conversation_id, start_ts, input, working_directory, output_status, model_id
-		FROM ai_queries 
-		ORDER BY start_ts DESC 
+		FROM ai_queries
+		ORDER BY start_ts DESC
 		LIMIT ?
 	`
 
-	rows, err := db.Query(query, limit)
+	rows, err := config.DB.QueryContext(r.Context(), query, limit)
 	if err != nil {
 		response := APIResponse{
 			Success: false,
@@ -247,16 +249,6 @@ func RecentAIQueriesHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer rows.Close()
 
-	type AIQuery struct {
-		ExchangeId       string     `json:"exchange_id"`
-		ConversationId   string     `json:"conversation_id"`
-		StartTs          *time.Time `json:"start_ts"`
-		Input            string     `json:"input"`
-		WorkingDirectory *string    `json:"working_directory"`
-		OutputStatus     string     `json:"output_status"`
-		ModelId          string     `json:"model_id"`
-	}
-
 	var queries []AIQuery
 	for rows.Next() {
 		var query AIQuery
@@ -267,6 +259,8 @@ func RecentAIQueriesHandler(w http.ResponseWriter, r *http.Request) {
 		queries = append(queries, query)
 	}
 
+	sortAIQueries(queries, parseSortSpec(r.URL.Query().Get("sort")))
+
 	response := APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
@@ -279,6 +273,142 @@ func RecentAIQueriesHandler(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, http.StatusOK, response)
 }
 
+// ImportCommandsHandler ingests command history from an external format
+// (Swagger/OpenAPI specs, shell history files, ...) and normalizes it into
+// the `commands` table, via the pluggable importer.Importer registry.
+func ImportCommandsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONResponse(w, http.StatusMethodNotAllowed, APIResponse{
+			Success: false,
+			Error:   "method not allowed",
+		})
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	imp, ok := importer.Get(format)
+	if !ok {
+		writeJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   (&importer.ErrUnknownFormat{Format: format}).Error(),
+		})
+		return
+	}
+
+	commands, err := imp.Parse(r.Body)
+	if err != nil {
+		writeJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("failed to parse %s import: %v", format, err),
+		})
+		return
+	}
+
+	config := LoadConfig()
+	if config.DB == nil {
+		writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "database pool is not available",
+		})
+		return
+	}
+
+	imported := 0
+	var importErrors []string
+	for _, cmd := range commands {
+		_, err := config.DB.ExecContext(r.Context(),
+			`INSERT INTO commands (command, exit_code, start_ts, completed_ts, pwd, shell, username, hostname)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			cmd.Command, cmd.ExitCode, cmd.StartTs, cmd.CompletedTs, cmd.Pwd, cmd.Shell, cmd.Username, cmd.Hostname,
+		)
+		if err != nil {
+			importErrors = append(importErrors, err.Error())
+			continue
+		}
+		imported++
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"format":   format,
+			"imported": imported,
+			"total":    len(commands),
+			"errors":   importErrors,
+		},
+	}
+
+	writeJSONResponse(w, http.StatusOK, response)
+}
+
+// ImportAIQueriesHandler ingests AI-query history from an external format
+// (currently Warp's own AI query export) and normalizes it into the
+// `ai_queries` table, via the pluggable importer.AIQueryImporter registry.
+func ImportAIQueriesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONResponse(w, http.StatusMethodNotAllowed, APIResponse{
+			Success: false,
+			Error:   "method not allowed",
+		})
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	imp, ok := importer.GetAIQueryImporter(format)
+	if !ok {
+		writeJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   (&importer.ErrUnknownFormat{Format: format}).Error(),
+		})
+		return
+	}
+
+	queries, err := imp.Parse(r.Body)
+	if err != nil {
+		writeJSONResponse(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("failed to parse %s import: %v", format, err),
+		})
+		return
+	}
+
+	config := LoadConfig()
+	if config.DB == nil {
+		writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "database pool is not available",
+		})
+		return
+	}
+
+	imported := 0
+	var importErrors []string
+	for _, query := range queries {
+		_, err := config.DB.ExecContext(r.Context(),
+			`INSERT INTO ai_queries (exchange_id, conversation_id, start_ts, input, working_directory, output_status, model_id)
+			 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			query.ExchangeId, query.ConversationId, query.StartTs, query.Input, query.WorkingDirectory, query.OutputStatus, query.ModelId,
+		)
+		if err != nil {
+			importErrors = append(importErrors, err.Error())
+			continue
+		}
+		imported++
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"format":   format,
+			"imported": imported,
+			"total":    len(queries),
+			"errors":   importErrors,
+		},
+	}
+
+	writeJSONResponse(w, http.StatusOK, response)
+}
+
 // writeJSONResponse is a helper function to write JSON responses
 func writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
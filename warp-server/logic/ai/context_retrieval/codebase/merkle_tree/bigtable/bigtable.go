@@ -2,76 +2,217 @@ package bigtable
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
-	"log"
+	"math"
+	"os"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/warpdotdev/warp-server/model/types/ai"
 )
 
-// Client represents a BigTable client
+// vecFamily is the column family WriteEmbeddings/ReadEmbedding store vectors
+// under.
+const vecFamily = "vec"
+
+// Client wraps a *bigtable.Client scoped to a single table.
 type Client struct {
 	ProjectID string
 	Instance  string
 	TableName string
+
+	bt    *bigtable.Client
+	table *bigtable.Table
 }
 
-// NewClient creates a new BigTable client
-func NewClient(projectID, instance, tableName string) *Client {
+// NewClient dials Cloud Bigtable and returns a Client scoped to tableName.
+// Extra option.ClientOptions (credentials, custom dialers, ...) are passed
+// straight through to bigtable.NewClient.
+func NewClient(ctx context.Context, projectID, instance, tableName string, opts ...option.ClientOption) (*Client, error) {
+	bt, err := bigtable.NewClient(ctx, projectID, instance, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("bigtable: dial project %q instance %q: %w", projectID, instance, err)
+	}
+
 	return &Client{
 		ProjectID: projectID,
 		Instance:  instance,
 		TableName: tableName,
+		bt:        bt,
+		table:     bt.Open(tableName),
+	}, nil
+}
+
+// NewEmulatorClient returns a Client pointed at the Bigtable emulator
+// addressed by BIGTABLE_EMULATOR_HOST, so tests can exercise a real RPC
+// round-trip without GCP credentials.
+func NewEmulatorClient(ctx context.Context, projectID, instance, tableName string) (*Client, error) {
+	addr := os.Getenv("BIGTABLE_EMULATOR_HOST")
+	if addr == "" {
+		return nil, fmt.Errorf("bigtable: BIGTABLE_EMULATOR_HOST is not set")
 	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("bigtable: dial emulator at %q: %w", addr, err)
+	}
+
+	return NewClient(ctx, projectID, instance, tableName, option.WithGRPCConn(conn))
 }
 
-// Row represents a BigTable row
+// Row represents a BigTable row, with Values keyed by "family:qualifier".
 type Row struct {
 	Key    string
 	Values map[string][]byte
 }
 
-// ReadRow reads a single row from BigTable
+// ReadRow reads a single row from BigTable, returning (nil, nil) if rowKey
+// doesn't exist.
 func (c *Client) ReadRow(ctx context.Context, rowKey string) (*Row, error) {
 	if rowKey == "" {
 		return nil, fmt.Errorf("row key cannot be empty")
 	}
 
-	// Mock implementation for testing
-	log.Printf("Reading row with key: %s from table: %s", rowKey, c.TableName)
-	
-	// Return a mock row
-	return &Row{
-		Key: rowKey,
-		Values: map[string][]byte{
-			"cf1:data": []byte("mock_data"),
-		},
-	}, nil
+	row, err := c.table.ReadRow(ctx, rowKey)
+	if err != nil {
+		return nil, fmt.Errorf("bigtable: read row %q: %w", rowKey, err)
+	}
+	if row == nil {
+		return nil, nil
+	}
+
+	values := make(map[string][]byte)
+	for _, items := range row {
+		for _, item := range items {
+			values[item.Column] = item.Value
+		}
+	}
+	return &Row{Key: rowKey, Values: values}, nil
 }
 
-// WriteRow writes a single row to BigTable
+// WriteRow writes a single row to BigTable. Each key of row.Values must be
+// of the form "family:qualifier".
 func (c *Client) WriteRow(ctx context.Context, row *Row) error {
 	if row == nil || row.Key == "" {
 		return fmt.Errorf("invalid row or empty key")
 	}
 
-	// Mock implementation for testing
-	log.Printf("Writing row with key: %s to table: %s", row.Key, c.TableName)
-	
+	mut := bigtable.NewMutation()
+	now := bigtable.Now()
+	for col, value := range row.Values {
+		family, qualifier, ok := strings.Cut(col, ":")
+		if !ok {
+			return fmt.Errorf("bigtable: column %q must be \"family:qualifier\"", col)
+		}
+		mut.Set(family, qualifier, now, value)
+	}
+
+	if err := c.table.Apply(ctx, row.Key, mut); err != nil {
+		return fmt.Errorf("bigtable: write row %q: %w", row.Key, err)
+	}
 	return nil
 }
 
-// DeleteRow deletes a single row from BigTable
+// DeleteRow deletes a single row from BigTable.
 func (c *Client) DeleteRow(ctx context.Context, rowKey string) error {
 	if rowKey == "" {
 		return fmt.Errorf("row key cannot be empty")
 	}
 
-	// Mock implementation for testing
-	log.Printf("Deleting row with key: %s from table: %s", rowKey, c.TableName)
-	
+	mut := bigtable.NewMutation()
+	mut.DeleteRow()
+	if err := c.table.Apply(ctx, rowKey, mut); err != nil {
+		return fmt.Errorf("bigtable: delete row %q: %w", rowKey, err)
+	}
 	return nil
 }
 
-// Close closes the BigTable client connection
-func (c *Client) Close() error {
-	log.Printf("Closing BigTable client for project: %s, instance: %s", c.ProjectID, c.Instance)
+// WriteEmbeddings persists embs for repoID in a single ApplyBulk call, one
+// row per embedding keyed as "{repoID}#{contentHash}" under the vec column
+// family. Vectors are packed as little-endian float32 rather than JSON to
+// keep row size down; use ReadEmbedding to decode them back.
+func (c *Client) WriteEmbeddings(ctx context.Context, repoID string, embs []ai.Embedding) error {
+	if len(embs) == 0 {
+		return nil
+	}
+
+	rowKeys := make([]string, len(embs))
+	muts := make([]*bigtable.Mutation, len(embs))
+	now := bigtable.Now()
+	for i, emb := range embs {
+		rowKeys[i] = repoID + "#" + emb.Hash
+
+		mut := bigtable.NewMutation()
+		mut.Set(vecFamily, "vector", now, encodeVector(emb.Vector))
+		mut.Set(vecFamily, "model", now, []byte(emb.Model))
+		muts[i] = mut
+	}
+
+	errs, err := c.table.ApplyBulk(ctx, rowKeys, muts)
+	if err != nil {
+		return fmt.Errorf("bigtable: write embeddings for repo %q: %w", repoID, err)
+	}
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("bigtable: write embedding %q: %w", rowKeys[i], err)
+		}
+	}
 	return nil
 }
+
+// ReadEmbedding reads back the embedding WriteEmbeddings stored for repoID
+// and contentHash, decoding its vector out of the compact binary encoding.
+// It returns (nil, nil) if no such row exists.
+func (c *Client) ReadEmbedding(ctx context.Context, repoID, contentHash string) (*ai.Embedding, error) {
+	row, err := c.ReadRow(ctx, repoID+"#"+contentHash)
+	if err != nil {
+		return nil, err
+	}
+	if row == nil {
+		return nil, nil
+	}
+
+	vector, err := decodeVector(row.Values[vecFamily+":vector"])
+	if err != nil {
+		return nil, fmt.Errorf("bigtable: decode vector for %q: %w", row.Key, err)
+	}
+
+	return &ai.Embedding{
+		Hash:      contentHash,
+		Vector:    vector,
+		Model:     string(row.Values[vecFamily+":model"]),
+		Dimension: len(vector),
+	}, nil
+}
+
+// Close closes the underlying BigTable client connection.
+func (c *Client) Close() error {
+	return c.bt.Close()
+}
+
+// encodeVector packs v as consecutive little-endian float32s.
+func encodeVector(v []float64) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(float32(f)))
+	}
+	return buf
+}
+
+// decodeVector is the inverse of encodeVector.
+func decodeVector(b []byte) ([]float64, error) {
+	if len(b)%4 != 0 {
+		return nil, fmt.Errorf("vector bytes length %d is not a multiple of 4", len(b))
+	}
+
+	v := make([]float64, len(b)/4)
+	for i := range v {
+		v[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:])))
+	}
+	return v, nil
+}
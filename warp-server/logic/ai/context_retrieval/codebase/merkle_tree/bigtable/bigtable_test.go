@@ -0,0 +1,75 @@
+package bigtable
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/warpdotdev/warp-server/model/types/ai"
+)
+
+func TestEncodeDecodeVector_RoundTrip(t *testing.T) {
+	original := []float64{0, 1.5, -2.25, 3.141592653589793}
+
+	encoded := encodeVector(original)
+	if len(encoded) != 4*len(original) {
+		t.Fatalf("expected %d encoded bytes, got %d", 4*len(original), len(encoded))
+	}
+
+	decoded, err := decodeVector(encoded)
+	if err != nil {
+		t.Fatalf("decodeVector() error = %v", err)
+	}
+	if len(decoded) != len(original) {
+		t.Fatalf("expected %d decoded values, got %d", len(original), len(decoded))
+	}
+
+	for i, want := range original {
+		if got := decoded[i]; float32(got) != float32(want) {
+			t.Errorf("decoded[%d] = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestDecodeVector_RejectsMisalignedInput(t *testing.T) {
+	if _, err := decodeVector([]byte{1, 2, 3}); err == nil {
+		t.Fatal("expected an error decoding a byte slice whose length isn't a multiple of 4")
+	}
+}
+
+func TestClient_ReadWriteDeleteRow_RejectEmptyKeys(t *testing.T) {
+	c := &Client{}
+
+	if _, err := c.ReadRow(context.Background(), ""); err == nil {
+		t.Error("ReadRow(\"\") should error")
+	}
+	if err := c.WriteRow(context.Background(), &Row{Key: ""}); err == nil {
+		t.Error("WriteRow with empty key should error")
+	}
+	if err := c.WriteRow(context.Background(), nil); err == nil {
+		t.Error("WriteRow(nil) should error")
+	}
+	if err := c.DeleteRow(context.Background(), ""); err == nil {
+		t.Error("DeleteRow(\"\") should error")
+	}
+}
+
+func TestClient_WriteEmbeddings_NoOpOnEmptyInput(t *testing.T) {
+	c := &Client{}
+
+	if err := c.WriteEmbeddings(context.Background(), "repo", nil); err != nil {
+		t.Errorf("WriteEmbeddings with no embeddings should be a no-op, got error: %v", err)
+	}
+	if err := c.WriteEmbeddings(context.Background(), "repo", []ai.Embedding{}); err != nil {
+		t.Errorf("WriteEmbeddings with no embeddings should be a no-op, got error: %v", err)
+	}
+}
+
+func TestNewEmulatorClient_RequiresEnvVar(t *testing.T) {
+	t.Setenv("BIGTABLE_EMULATOR_HOST", "")
+	os.Unsetenv("BIGTABLE_EMULATOR_HOST")
+
+	if _, err := NewEmulatorClient(context.Background(), "project", "instance", "table"); err == nil {
+		t.Fatal("expected NewEmulatorClient to error when BIGTABLE_EMULATOR_HOST is unset")
+	}
+}
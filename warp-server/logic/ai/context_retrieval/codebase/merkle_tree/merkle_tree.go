@@ -1,8 +1,17 @@
+// Package merkle_tree parses and formats the row keys used to address nodes
+// of the codebase merkle tree (file/directory/root nodes, node-to-leaf
+// mappings, and root metadata) in the underlying key-value store.
 package merkle_tree
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+
+	"github.com/warpdotdev/warp-server/pkg/comparator"
 )
 
 // NodeType represents different types of nodes in the merkle tree
@@ -13,138 +22,356 @@ const (
 	File
 	Directory
 	Root
-	N2L    // Node to Leaf
-	RMeta  // Root Metadata
+	N2L   // Node to Leaf
+	RMeta // Root Metadata
 )
 
-// RowKey represents a parsed row key for the merkle tree
+// String returns the string representation of the node type.
+func (nt NodeType) String() string {
+	switch nt {
+	case File:
+		return "file"
+	case Directory:
+		return "directory"
+	case Root:
+		return "root"
+	case N2L:
+		return "n2l"
+	case RMeta:
+		return "rmeta"
+	default:
+		return "unknown"
+	}
+}
+
+// schema is a compiled row-key grammar registered via RegisterSchema.
+type schema struct {
+	name     string
+	pattern  string
+	nodeType NodeType
+	re       *regexp.Regexp
+	fields   []string // capture group names, in pattern order
+
+	hashLen      int      // expected length of the "hash" field; 0 means unconstrained
+	allowedKinds []string // allowed values for the "kind" field; nil means unconstrained
+}
+
+// SchemaOption configures optional validation constraints on a schema
+// registered via RegisterSchema. Constraints are enforced by RowKey.Validate,
+// not by ParseRowKey, so a row key can still be parsed (and reported) even if
+// it fails a constraint.
+type SchemaOption func(*schema)
+
+// WithHashLength requires the schema's "hash" field to be exactly n
+// characters long, e.g. WithHashLength(40) for a hex-encoded SHA-1.
+func WithHashLength(n int) SchemaOption {
+	return func(s *schema) {
+		s.hashLen = n
+	}
+}
+
+// WithAllowedKinds requires the schema's "kind" field to be one of kinds.
+func WithAllowedKinds(kinds ...string) SchemaOption {
+	return func(s *schema) {
+		s.allowedKinds = kinds
+	}
+}
+
+var placeholderRe = regexp.MustCompile(`\{(\w+)\}`)
+
+// compileSchema turns a pattern like "{hash}:node_type:{kind}" into a regexp
+// with one unnamed, non-greedy capture group per placeholder, recording the
+// placeholder names in pattern order in schema.fields (the final
+// placeholder is greedy so it captures the remainder of the key, including
+// any delimiter-like characters it may itself contain).
+func compileSchema(name, pattern string) (*schema, error) {
+	matches := placeholderRe.FindAllStringSubmatchIndex(pattern, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("schema %q has no {field} placeholders", name)
+	}
+
+	var out strings.Builder
+	out.WriteString("^")
+	fields := make([]string, 0, len(matches))
+	pos := 0
+	for i, m := range matches {
+		literalStart, literalEnd := pos, m[0]
+		out.WriteString(regexp.QuoteMeta(pattern[literalStart:literalEnd]))
+
+		field := pattern[m[2]:m[3]]
+		fields = append(fields, field)
+		if i == len(matches)-1 {
+			out.WriteString("(.*)")
+		} else {
+			out.WriteString("(.*?)")
+		}
+
+		pos = m[1]
+	}
+	out.WriteString(regexp.QuoteMeta(pattern[pos:]))
+	out.WriteString("$")
+
+	re, err := regexp.Compile(out.String())
+	if err != nil {
+		return nil, fmt.Errorf("schema %q compiled to invalid regexp: %w", name, err)
+	}
+
+	return &schema{
+		name:     name,
+		pattern:  pattern,
+		nodeType: inferNodeType(pattern),
+		re:       re,
+		fields:   fields,
+	}, nil
+}
+
+// inferNodeType derives a NodeType from a schema's own (static) pattern
+// rather than the dynamic row key being parsed. Because the pattern is known
+// at registration time, matching on it can't be shadowed the way matching on
+// an arbitrary raw key could (e.g. "rmeta_node_type" no longer gets
+// mis-detected as plain "node_type").
+func inferNodeType(pattern string) NodeType {
+	switch {
+	case strings.Contains(pattern, "n2l_node_type"):
+		return N2L
+	case strings.Contains(pattern, "rmeta_node_type"):
+		return RMeta
+	case strings.Contains(pattern, "node_type"):
+		return File
+	case strings.Contains(pattern, "directory"):
+		return Directory
+	case strings.Contains(pattern, "root"):
+		return Root
+	default:
+		return Unknown
+	}
+}
+
+var (
+	schemaMu      sync.RWMutex
+	schemasByName = map[string]*schema{}
+	schemaOrder   []*schema
+)
+
+// RegisterSchema registers a named row-key grammar, e.g.
+//
+//	RegisterSchema("file_colon", "{hash}:node_type:{kind}")
+//	RegisterSchema("n2l_hash", "{hash}#n2l_node_type#{leaf}")
+//
+// Schemas are tried by ParseRowKey in registration order, and the first one
+// whose pattern matches the row key wins. Registering a name that already
+// exists replaces the previous schema in place (its position in the
+// registration order is preserved).
+//
+// opts attaches schema-specific constraints (WithHashLength,
+// WithAllowedKinds) that RowKey.Validate enforces for row keys parsed under
+// this schema.
+func RegisterSchema(name, pattern string, opts ...SchemaOption) error {
+	s, err := compileSchema(name, pattern)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+
+	if existing, ok := schemasByName[name]; ok {
+		for i, candidate := range schemaOrder {
+			if candidate == existing {
+				schemaOrder[i] = s
+				break
+			}
+		}
+	} else {
+		schemaOrder = append(schemaOrder, s)
+	}
+	schemasByName[name] = s
+
+	return nil
+}
+
+func lookupSchema(name string) (*schema, bool) {
+	schemaMu.RLock()
+	defer schemaMu.RUnlock()
+	s, ok := schemasByName[name]
+	return s, ok
+}
+
+func init() {
+	defaults := []struct{ name, pattern string }{
+		{"file_colon", "{hash}:node_type:{kind}"},
+		{"file_hash", "{hash}#node_type#{kind}"},
+		{"file_slash", "{hash}/node_type/{kind}"},
+		{"n2l_colon", "{hash}:n2l_node_type:{leaf}"},
+		{"n2l_hash", "{hash}#n2l_node_type#{leaf}"},
+		{"n2l_slash", "{hash}/n2l_node_type/{leaf}"},
+		{"n2l_underscore", "{hash}_n2l_node_type_{leaf}"},
+		{"rmeta_colon", "{hash}:rmeta_node_type:{kind}"},
+		{"rmeta_hash", "{hash}#rmeta_node_type#{kind}"},
+		{"rmeta_slash", "{hash}/rmeta_node_type/{kind}"},
+		{"rmeta_underscore", "{hash}_rmeta_node_type_{kind}"},
+		{"directory_colon", "{hash}:directory:{kind}"},
+		{"directory_hash", "{hash}#directory#{kind}"},
+		{"directory_slash", "{hash}/directory/{kind}"},
+		{"root_colon", "{hash}:root:{kind}"},
+		{"root_hash", "{hash}#root#{kind}"},
+		{"root_slash", "{hash}/root/{kind}"},
+	}
+
+	for _, d := range defaults {
+		if err := RegisterSchema(d.name, d.pattern); err != nil {
+			panic(fmt.Sprintf("merkle_tree: invalid default schema %q: %v", d.name, err))
+		}
+	}
+}
+
+// RowKey represents a parsed row key for the merkle tree.
 type RowKey struct {
-	NodeType   NodeType
-	Hash       string
-	Segments   []string
-	Delimiters []string
+	NodeType NodeType
+	Hash     string
+	// SchemaName is the name of the schema that matched this row key; pass
+	// it back to Format to round-trip the key.
+	SchemaName string
+	// Fields holds every named capture from the matched schema, keyed by
+	// placeholder name (always includes "hash").
+	Fields map[string]string
 }
 
-// ParseRowKey parses a row key string into a RowKey struct
+// ParseRowKey parses a row key string into a RowKey struct, trying every
+// registered schema in registration order and returning the first match.
 func ParseRowKey(rowKey string) (*RowKey, error) {
 	if rowKey == "" {
 		return nil, errors.New("empty row key")
 	}
 
-	// Split by common delimiters
-	delimiters := []string{":", "/", "#", "_"}
-	segments := []string{rowKey}
-	usedDelimiters := []string{}
+	schemaMu.RLock()
+	order := make([]*schema, len(schemaOrder))
+	copy(order, schemaOrder)
+	schemaMu.RUnlock()
 
-	for _, delimiter := range delimiters {
-		newSegments := []string{}
-		newDelimiters := []string{}
-		
-		for i, segment := range segments {
-			if i > 0 {
-				newDelimiters = append(newDelimiters, usedDelimiters[i-1])
-			}
-			
-			parts := strings.Split(segment, delimiter)
-			if len(parts) > 1 {
-				for j, part := range parts {
-					if j > 0 {
-						newDelimiters = append(newDelimiters, delimiter)
-					}
-					newSegments = append(newSegments, part)
-				}
-			} else {
-				newSegments = append(newSegments, segment)
-			}
+	for _, s := range order {
+		match := s.re.FindStringSubmatch(rowKey)
+		if match == nil {
+			continue
 		}
-		
-		segments = newSegments
-		usedDelimiters = newDelimiters
-	}
-
-	// Validate minimum segments
-	if len(segments) < 1 {
-		return nil, errors.New("insufficient segments in row key")
-	}
-
-	// Determine node type and extract hash
-	nodeType := Unknown
-	hash := ""
-	
-	if len(segments) > 0 {
-		switch {
-		case strings.Contains(rowKey, "node_type"):
-			nodeType = File
-		case strings.Contains(rowKey, "n2l_node_type"):
-			nodeType = N2L
-		case strings.Contains(rowKey, "rmeta_node_type"):
-			nodeType = RMeta
-		case strings.Contains(rowKey, "directory"):
-			nodeType = Directory
-		case strings.Contains(rowKey, "root"):
-			nodeType = Root
+
+		fields := make(map[string]string, len(s.fields))
+		for i, name := range s.fields {
+			fields[name] = match[i+1]
 		}
-		
-		// Extract hash from segments (usually the first or second segment)
-		for _, segment := range segments {
-			if len(segment) > 0 && segment != "node_type" && segment != "n2l_node_type" && segment != "rmeta_node_type" {
-				hash = segment
-				break
-			}
+
+		return &RowKey{
+			NodeType:   s.nodeType,
+			Hash:       fields["hash"],
+			SchemaName: s.name,
+			Fields:     fields,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no registered schema matches row key %q", rowKey)
+}
+
+// Format re-encodes the RowKey's Fields using the named schema, allowing a
+// RowKey parsed under one schema to be round-tripped (or re-encoded into a
+// differently-delimited schema, as long as it captured the same fields).
+func (rk *RowKey) Format(schemaName string) (string, error) {
+	s, ok := lookupSchema(schemaName)
+	if !ok {
+		return "", fmt.Errorf("unknown schema %q", schemaName)
+	}
+
+	result := s.pattern
+	for _, name := range s.fields {
+		value, ok := rk.Fields[name]
+		if !ok {
+			return "", fmt.Errorf("row key is missing field %q required by schema %q", name, schemaName)
 		}
+		result = strings.Replace(result, "{"+name+"}", value, 1)
 	}
 
-	return &RowKey{
-		NodeType:   nodeType,
-		Hash:       hash,
-		Segments:   segments,
-		Delimiters: usedDelimiters,
-	}, nil
+	return result, nil
 }
 
-// String returns the string representation of the RowKey
+// String returns the string representation of the RowKey, round-tripped
+// through the schema it was originally parsed with.
 func (rk *RowKey) String() string {
-	result := ""
-	for i, segment := range rk.Segments {
-		if i > 0 && i-1 < len(rk.Delimiters) {
-			result += rk.Delimiters[i-1]
-		}
-		result += segment
+	encoded, err := rk.Format(rk.SchemaName)
+	if err != nil {
+		return ""
 	}
-	return result
+	return encoded
 }
 
-// Validate checks if the RowKey is valid
+// Validate checks if the RowKey is valid. The hash segment must be
+// non-empty, and kind-like fields must not be empty either, so a key like
+// ":node_type:file" parses (it matches the schema grammar) but fails
+// validation. Validation is also schema-aware: if the schema the row key was
+// parsed with (rk.SchemaName) was registered with WithHashLength or
+// WithAllowedKinds, those constraints are enforced here too.
 func (rk *RowKey) Validate() error {
 	if rk.Hash == "" {
 		return errors.New("hash segment is empty")
 	}
-	
-	if len(rk.Segments) == 0 {
-		return errors.New("no segments found")
-	}
-	
+
 	if rk.NodeType == Unknown {
 		return errors.New("unknown node type")
 	}
-	
+
+	for name, value := range rk.Fields {
+		if name != "hash" && value == "" {
+			return fmt.Errorf("field %q is empty", name)
+		}
+	}
+
+	if s, ok := lookupSchema(rk.SchemaName); ok {
+		if s.hashLen > 0 && len(rk.Hash) != s.hashLen {
+			return fmt.Errorf("hash %q has length %d, want %d for schema %q", rk.Hash, len(rk.Hash), s.hashLen, s.name)
+		}
+		if len(s.allowedKinds) > 0 {
+			kind := rk.Fields["kind"]
+			if !slices.Contains(s.allowedKinds, kind) {
+				return fmt.Errorf("kind %q is not one of %v for schema %q", kind, s.allowedKinds, s.name)
+			}
+		}
+	}
+
 	return nil
 }
 
-// GetNodeTypeString returns the string representation of the node type
+// GetNodeTypeString returns the string representation of the node type.
+//
+// Deprecated: use NodeType.String() directly.
 func (rk *RowKey) GetNodeTypeString() string {
-	switch rk.NodeType {
-	case File:
-		return "file"
-	case Directory:
-		return "directory"
-	case Root:
-		return "root"
-	case N2L:
-		return "n2l"
-	case RMeta:
-		return "rmeta"
-	default:
-		return "unknown"
+	return rk.NodeType.String()
+}
+
+// CompareTo orders rk relative to other using cmp, which is expected to
+// accept two *RowKey values (e.g. CompareByHash, CompareByNodeType, or a
+// CompareByField(name) comparator). This lets callers sort Bigtable-style
+// row ranges by hash, by node type, or by any captured field, without
+// hand-rolling a sort.Interface per ordering.
+func (rk *RowKey) CompareTo(other *RowKey, cmp comparator.Comparator) int {
+	return cmp(rk, other)
+}
+
+// CompareByHash orders RowKeys lexicographically by Hash.
+func CompareByHash(a, b interface{}) int {
+	return comparator.CompareStrings(a.(*RowKey).Hash, b.(*RowKey).Hash)
+}
+
+// CompareByNodeType orders RowKeys by their NodeType enum value.
+func CompareByNodeType(a, b interface{}) int {
+	return comparator.CompareInts(int(a.(*RowKey).NodeType), int(b.(*RowKey).NodeType))
+}
+
+// CompareByField returns a Comparator that orders RowKeys by the string
+// value of the named captured field (e.g. "kind", "leaf"). RowKeys that
+// don't have the named field sort as if it were empty.
+func CompareByField(name string) comparator.Comparator {
+	return func(a, b interface{}) int {
+		return comparator.CompareStrings(a.(*RowKey).Fields[name], b.(*RowKey).Fields[name])
 	}
 }
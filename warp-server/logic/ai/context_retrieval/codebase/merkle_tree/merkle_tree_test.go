@@ -6,29 +6,39 @@ import (
 
 func TestParseRowKey(t *testing.T) {
 	tests := []struct {
-		name    string
-		rowKey  string
-		wantErr bool
+		name         string
+		rowKey       string
+		wantErr      bool
+		wantNodeType NodeType
+		wantHash     string
 	}{
 		{
-			name:    "Valid_rowKey_with_node_type",
-			rowKey:  "hash123:node_type:file",
-			wantErr: false,
+			name:         "Valid_rowKey_with_node_type",
+			rowKey:       "hash123:node_type:file",
+			wantErr:      false,
+			wantNodeType: File,
+			wantHash:     "hash123",
 		},
 		{
-			name:    "Valid_rowKey_with_node_type#01",
-			rowKey:  "hash456#node_type#directory",
-			wantErr: false,
+			name:         "Valid_rowKey_with_node_type#01",
+			rowKey:       "hash456#node_type#directory",
+			wantErr:      false,
+			wantNodeType: File,
+			wantHash:     "hash456",
 		},
 		{
-			name:    "Valid_rowKey_with_n2l_node_type",
-			rowKey:  "hash789/n2l_node_type/leaf",
-			wantErr: false,
+			name:         "Valid_rowKey_with_n2l_node_type",
+			rowKey:       "hash789/n2l_node_type/leaf",
+			wantErr:      false,
+			wantNodeType: N2L,
+			wantHash:     "hash789",
 		},
 		{
-			name:    "Valid_rowKey_with_rmeta_node_type",
-			rowKey:  "hash_abc_rmeta_node_type_metadata",
-			wantErr: false,
+			name:         "Valid_rowKey_with_rmeta_node_type",
+			rowKey:       "hash_abc_rmeta_node_type_metadata",
+			wantErr:      false,
+			wantNodeType: RMeta,
+			wantHash:     "hash_abc",
 		},
 		{
 			name:    "Invalid_rowKey_with_insufficient_segments",
@@ -41,9 +51,11 @@ func TestParseRowKey(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name:    "RowKey_with_excessive_delimiters",
-			rowKey:  "hash:::node_type:::file:::extra",
-			wantErr: false,
+			name:         "RowKey_with_excessive_delimiters",
+			rowKey:       "hash:::node_type:::file:::extra",
+			wantErr:      false,
+			wantNodeType: File,
+			wantHash:     "hash::",
 		},
 		{
 			name:    "RowKey_with_empty_hash_segment",
@@ -59,24 +71,159 @@ func TestParseRowKey(t *testing.T) {
 				t.Errorf("ParseRowKey() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			
+
 			if !tt.wantErr && rowKey == nil {
 				t.Errorf("ParseRowKey() returned nil without error")
 				return
 			}
-			
+
 			if !tt.wantErr {
-				// Additional validation for successful parses
-				if len(rowKey.Segments) == 0 {
-					t.Errorf("ParseRowKey() returned empty segments")
+				if rowKey.Hash != tt.wantHash {
+					t.Errorf("ParseRowKey() hash = %q, want %q", rowKey.Hash, tt.wantHash)
+				}
+
+				if tt.wantNodeType != Unknown && rowKey.NodeType != tt.wantNodeType {
+					t.Errorf("ParseRowKey() node type = %v, want %v", rowKey.NodeType, tt.wantNodeType)
 				}
-				
-				// Test that we can convert back to string
+
+				// Test that we can round-trip back to the original string.
 				reconstructed := rowKey.String()
-				if reconstructed == "" {
-					t.Errorf("RowKey.String() returned empty string")
+				if reconstructed != tt.rowKey {
+					t.Errorf("RowKey.String() = %q, want %q", reconstructed, tt.rowKey)
 				}
 			}
 		})
 	}
 }
+
+func TestRegisterSchema(t *testing.T) {
+	if err := RegisterSchema("test_custom", "{hash}|custom_node_type|{kind}"); err != nil {
+		t.Fatalf("RegisterSchema() error = %v", err)
+	}
+
+	rowKey, err := ParseRowKey("deadbeef|custom_node_type|widget")
+	if err != nil {
+		t.Fatalf("ParseRowKey() error = %v", err)
+	}
+
+	if rowKey.Hash != "deadbeef" || rowKey.Fields["kind"] != "widget" {
+		t.Errorf("unexpected parse result: %+v", rowKey)
+	}
+
+	if rowKey.SchemaName != "test_custom" {
+		t.Errorf("SchemaName = %q, want %q", rowKey.SchemaName, "test_custom")
+	}
+}
+
+func TestRowKey_FormatAcrossSchemas(t *testing.T) {
+	rowKey, err := ParseRowKey("hash123:node_type:file")
+	if err != nil {
+		t.Fatalf("ParseRowKey() error = %v", err)
+	}
+
+	encoded, err := rowKey.Format("file_hash")
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	want := "hash123#node_type#file"
+	if encoded != want {
+		t.Errorf("Format() = %q, want %q", encoded, want)
+	}
+}
+
+func TestRowKey_Validate(t *testing.T) {
+	rowKey, err := ParseRowKey(":node_type:file")
+	if err != nil {
+		t.Fatalf("ParseRowKey() error = %v", err)
+	}
+
+	if err := rowKey.Validate(); err == nil {
+		t.Errorf("Validate() expected error for empty hash segment")
+	}
+}
+
+func TestRowKey_Validate_EnforcesSchemaHashLength(t *testing.T) {
+	if err := RegisterSchema("test_fixed_hash", "{hash}|fixed_hash_node_type|{kind}", WithHashLength(8)); err != nil {
+		t.Fatalf("RegisterSchema() error = %v", err)
+	}
+
+	short, err := ParseRowKey("deadbeef|fixed_hash_node_type|widget")
+	if err != nil {
+		t.Fatalf("ParseRowKey() error = %v", err)
+	}
+	if err := short.Validate(); err != nil {
+		t.Errorf("Validate() with an 8-char hash should pass WithHashLength(8), got error: %v", err)
+	}
+
+	wrongLen, err := ParseRowKey("dead|fixed_hash_node_type|widget")
+	if err != nil {
+		t.Fatalf("ParseRowKey() error = %v", err)
+	}
+	if err := wrongLen.Validate(); err == nil {
+		t.Error("Validate() expected error for a hash that doesn't match WithHashLength(8)")
+	}
+}
+
+func TestRowKey_Validate_EnforcesSchemaAllowedKinds(t *testing.T) {
+	if err := RegisterSchema("test_allowed_kinds", "{hash}|allowed_kinds_node_type|{kind}", WithAllowedKinds("image", "video")); err != nil {
+		t.Fatalf("RegisterSchema() error = %v", err)
+	}
+
+	allowed, err := ParseRowKey("deadbeef|allowed_kinds_node_type|image")
+	if err != nil {
+		t.Fatalf("ParseRowKey() error = %v", err)
+	}
+	if err := allowed.Validate(); err != nil {
+		t.Errorf("Validate() with kind %q should be allowed, got error: %v", "image", err)
+	}
+
+	disallowed, err := ParseRowKey("deadbeef|allowed_kinds_node_type|widget")
+	if err != nil {
+		t.Fatalf("ParseRowKey() error = %v", err)
+	}
+	if err := disallowed.Validate(); err == nil {
+		t.Error("Validate() expected error for a kind not in WithAllowedKinds")
+	}
+}
+
+func TestAmbiguousNodeTypesNoLongerShadow(t *testing.T) {
+	n2l, err := ParseRowKey("hash1_n2l_node_type_leaf1")
+	if err != nil {
+		t.Fatalf("ParseRowKey() error = %v", err)
+	}
+	if n2l.NodeType != N2L {
+		t.Errorf("expected N2L, got %v", n2l.NodeType)
+	}
+
+	rmeta, err := ParseRowKey("hash2_rmeta_node_type_meta2")
+	if err != nil {
+		t.Fatalf("ParseRowKey() error = %v", err)
+	}
+	if rmeta.NodeType != RMeta {
+		t.Errorf("expected RMeta, got %v", rmeta.NodeType)
+	}
+}
+
+func TestRowKey_CompareTo(t *testing.T) {
+	a, err := ParseRowKey("a1:node_type:file")
+	if err != nil {
+		t.Fatalf("ParseRowKey() error = %v", err)
+	}
+	b, err := ParseRowKey("b2/n2l_node_type/leaf")
+	if err != nil {
+		t.Fatalf("ParseRowKey() error = %v", err)
+	}
+
+	if got := a.CompareTo(b, CompareByHash); got >= 0 {
+		t.Errorf("CompareByHash: expected a < b, got %d", got)
+	}
+
+	if got := a.CompareTo(b, CompareByNodeType); got >= 0 {
+		t.Errorf("CompareByNodeType: expected File(%d) < N2L(%d), got %d", File, N2L, got)
+	}
+
+	if got := a.CompareTo(a, CompareByHash); got != 0 {
+		t.Errorf("CompareByHash: expected a == a, got %d", got)
+	}
+}
@@ -0,0 +1,40 @@
+package caching_generator
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/warpdotdev/warp-server/pkg/retry"
+)
+
+// EmbeddingBackend turns a batch of texts into vector embeddings using a
+// specific provider (OpenAI, Cohere, a local ONNX runtime, ...). model is
+// passed through verbatim to the provider's API, unmodified.
+type EmbeddingBackend interface {
+	Embed(ctx context.Context, model string, texts []string) ([][]float32, error)
+}
+
+// NewBackend selects an EmbeddingBackend for the given provider name (as set
+// via EmbeddingConfig.Provider, e.g. "openai", "cohere", or "onnx"). The
+// model to embed with is a separate, unprefixed EmbeddingConfig.Model string
+// passed to EmbeddingBackend.Embed, mirroring the fragment_embedding
+// package's Provider/Model split.
+func NewBackend(provider string) (EmbeddingBackend, error) {
+	switch provider {
+	case "openai":
+		return NewOpenAIBackend(os.Getenv("OPENAI_API_KEY")), nil
+	case "cohere":
+		return NewCohereBackend(os.Getenv("COHERE_API_KEY")), nil
+	case "onnx":
+		return NewONNXBackend(), nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", provider)
+	}
+}
+
+// withRetry calls fn up to maxAttempts times, backing off exponentially
+// (with jitter) between attempts, and gives up early if ctx is cancelled.
+func withRetry(ctx context.Context, maxAttempts int, fn func() error) error {
+	return retry.Do(ctx, maxAttempts, fn)
+}
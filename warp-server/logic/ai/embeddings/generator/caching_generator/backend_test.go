@@ -0,0 +1,76 @@
+package caching_generator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenAIBackend_Embed_SendsUnprefixedModel(t *testing.T) {
+	var gotModel string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIEmbeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		gotModel = req.Model
+		json.NewEncoder(w).Encode(openAIEmbeddingResponse{
+			Data: []struct {
+				Embedding []float32 `json:"embedding"`
+				Index     int       `json:"index"`
+			}{{Embedding: []float32{0.1, 0.2}, Index: 0}},
+		})
+	}))
+	defer server.Close()
+
+	backend := NewOpenAIBackend("test-key")
+	backend.BaseURL = server.URL
+
+	if _, err := backend.Embed(context.Background(), "text-embedding-3-small", []string{"hello"}); err != nil {
+		t.Fatalf("Embed returned error: %v", err)
+	}
+
+	if gotModel != "text-embedding-3-small" {
+		t.Errorf("expected outgoing model %q, got %q", "text-embedding-3-small", gotModel)
+	}
+}
+
+func TestCohereBackend_Embed_SendsUnprefixedModel(t *testing.T) {
+	var gotModel string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req cohereEmbeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		gotModel = req.Model
+		json.NewEncoder(w).Encode(cohereEmbeddingResponse{Embeddings: [][]float32{{0.1, 0.2}}})
+	}))
+	defer server.Close()
+
+	backend := NewCohereBackend("test-key")
+	backend.BaseURL = server.URL
+
+	if _, err := backend.Embed(context.Background(), "embed-english-v3.0", []string{"hello"}); err != nil {
+		t.Fatalf("Embed returned error: %v", err)
+	}
+
+	if gotModel != "embed-english-v3.0" {
+		t.Errorf("expected outgoing model %q, got %q", "embed-english-v3.0", gotModel)
+	}
+}
+
+func TestNewBackend_SelectsProviderWithoutTouchingModel(t *testing.T) {
+	backend, err := NewBackend("openai")
+	if err != nil {
+		t.Fatalf("NewBackend returned error: %v", err)
+	}
+	if _, ok := backend.(*OpenAIBackend); !ok {
+		t.Fatalf("expected *OpenAIBackend, got %T", backend)
+	}
+
+	if _, err := NewBackend("unknown"); err == nil {
+		t.Error("expected error for unknown provider, got nil")
+	}
+}
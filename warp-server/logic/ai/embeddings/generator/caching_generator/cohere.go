@@ -0,0 +1,78 @@
+package caching_generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// CohereBackend calls Cohere's /v1/embed endpoint.
+type CohereBackend struct {
+	APIKey     string
+	BaseURL    string
+	InputType  string
+	HTTPClient *http.Client
+	MaxRetries int
+}
+
+// NewCohereBackend returns a CohereBackend authenticated with apiKey.
+func NewCohereBackend(apiKey string) *CohereBackend {
+	return &CohereBackend{
+		APIKey:     apiKey,
+		BaseURL:    "https://api.cohere.ai/v1",
+		InputType:  "search_document",
+		HTTPClient: http.DefaultClient,
+		MaxRetries: 3,
+	}
+}
+
+type cohereEmbeddingRequest struct {
+	Model     string   `json:"model"`
+	Texts     []string `json:"texts"`
+	InputType string   `json:"input_type"`
+}
+
+type cohereEmbeddingResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// Embed implements EmbeddingBackend.
+func (b *CohereBackend) Embed(ctx context.Context, model string, texts []string) ([][]float32, error) {
+	var vectors [][]float32
+
+	err := withRetry(ctx, b.MaxRetries, func() error {
+		payload, err := json.Marshal(cohereEmbeddingRequest{Model: model, Texts: texts, InputType: b.InputType})
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.BaseURL+"/embed", bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+b.APIKey)
+
+		resp, err := b.HTTPClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("cohere embed request failed with status %d", resp.StatusCode)
+		}
+
+		var parsed cohereEmbeddingResponse
+		if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+			return err
+		}
+
+		vectors = parsed.Embeddings
+		return nil
+	})
+
+	return vectors, err
+}
@@ -0,0 +1,21 @@
+package caching_generator
+
+// FailureReason enumerates why a fragment's embedding could not be produced
+// or stored.
+type FailureReason string
+
+const (
+	FailureRateLimited       FailureReason = "rate_limited"
+	FailureInvalidInput      FailureReason = "invalid"
+	FailureDimensionMismatch FailureReason = "dimension_mismatch"
+)
+
+// EmbeddingFailure describes a single fragment whose embedding was rejected.
+// This mirrors the APNS feedback-service pattern: a side channel reporting
+// which items failed downstream, so the caller can retry them or evict them
+// from CheckFragmentEmbeddingsExist.
+type EmbeddingFailure struct {
+	Hash   string
+	Reason FailureReason
+	Err    error
+}
@@ -2,65 +2,291 @@ package caching_generator
 
 import (
 	"context"
+	"fmt"
+	"runtime"
+
 	"github.com/warpdotdev/warp-server/model/types"
 	"github.com/warpdotdev/warp-server/model/types/ai"
 	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
 )
 
+// feedbackBufferSize bounds how many unread EmbeddingFailures Feedback()
+// will hold before GenerateEmbeddings starts dropping new ones rather than
+// blocking on a slow (or absent) consumer.
+const feedbackBufferSize = 64
+
 // Generator is the structure for caching generator
 type Generator struct {
-	store Store
+	store    Store
+	backend  EmbeddingBackend
+	feedback chan EmbeddingFailure
 }
 
 // Store interface defines methods for store operations
 type Store interface {
 	CheckFragmentEmbeddingsExist(ctx context.Context, embeddingConfig graphql_types.EmbeddingConfig, repoMetadata types.RepoMetadata, hashes []string, userUID string) ([]ai.ContentHashJbool, error)
-	StoreFragmentEmbeddings(ctx context.Context, embeddingConfig graphql_types.EmbeddingConfig, repoMetadata types.RepoMetadata, graphql_types types.GraphQLTypes) error
+
+	// GuardedUpdate atomically reads the embedding currently stored under
+	// key (nil if there is none), passes it to tryUpdate, and commits the
+	// result conditioned on the key's version not having changed in the
+	// meantime. Implementations are expected to retry tryUpdate on a
+	// conflicting write, the same way an etcd3 compare-and-swap loop would;
+	// callers only need to know that a non-nil error means the update did
+	// not take effect.
+	GuardedUpdate(ctx context.Context, key string, tryUpdate func(current *ai.Embedding) (*ai.Embedding, error)) error
 }
 
-// New creates a new caching generator
+// New creates a new caching generator. The embedding backend is resolved
+// per call from EmbeddingConfig.Provider; use NewWithBackend to pin a
+// specific backend instead (e.g. in tests).
 func New(store Store) *Generator {
-	return &Generator{store: store}
+	return &Generator{store: store, feedback: make(chan EmbeddingFailure, feedbackBufferSize)}
+}
+
+// NewWithBackend creates a caching generator that always uses backend,
+// rather than resolving one from EmbeddingConfig.Provider on every call.
+func NewWithBackend(store Store, backend EmbeddingBackend) *Generator {
+	g := New(store)
+	g.backend = backend
+	return g
+}
+
+// Feedback returns a stream of fragments whose embeddings were rejected
+// (rate-limited, invalid, or a dimension mismatch) so the caller can retry
+// them or evict them from CheckFragmentEmbeddingsExist. The channel is never
+// closed by the generator.
+func (g *Generator) Feedback() <-chan EmbeddingFailure {
+	return g.feedback
+}
+
+func (g *Generator) emitFailure(f EmbeddingFailure) {
+	select {
+	case g.feedback <- f:
+	default:
+		// Feedback is best-effort observability; never block generation on
+		// a slow or absent consumer.
+	}
 }
 
-// GenerateEmbeddings is a mock implementation of the embedding generation function
+// GenerateEmbeddings generates embeddings for the fragments that don't
+// already have one stored. Pending fragments are chunked according to
+// embeddingConfig.BatchSize and the batches are issued concurrently against
+// the embedding backend, bounded to GOMAXPROCS workers at a time. If one
+// batch errors, embeddings already computed by the others are still stored
+// and returned; the result's Success/Error fields reflect whether any batch
+// failed, and callers that need per-fragment detail can use Feedback.
 func (g *Generator) GenerateEmbeddings(ctx context.Context, embeddingConfig graphql_types.EmbeddingConfig, repoMetadata types.RepoMetadata, fragments []types.Fragment, userUID string) (*ai.EmbeddingResult, error) {
 	if len(fragments) == 0 {
 		return &ai.EmbeddingResult{Embeddings: []ai.Embedding{}, Success: true}, nil
 	}
 
-	// For simplicity, mock check and store operations without real embedding logic
 	existing, err := g.store.CheckFragmentEmbeddingsExist(ctx, embeddingConfig, repoMetadata, extractHashes(fragments), userUID)
 	if err != nil {
 		return nil, err
 	}
-	
-	newEmbeddings := []ai.Embedding{}
+
+	existsByHash := make(map[string]bool, len(existing))
+	for _, e := range existing {
+		if e.Exists {
+			existsByHash[e.Hash] = true
+		}
+	}
+
+	var pending []types.Fragment
 	for _, fragment := range fragments {
-		found := false
-		for _, e := range existing {
-			if e.Hash == fragment.Hash && e.Exists {
-				found = true
-				break
-			}
+		if !existsByHash[fragment.Hash] {
+			pending = append(pending, fragment)
+		}
+	}
+
+	if len(pending) == 0 {
+		return &ai.EmbeddingResult{Embeddings: []ai.Embedding{}, Success: true}, nil
+	}
+
+	backend := g.backend
+	if backend == nil {
+		backend, err = NewBackend(embeddingConfig.Provider)
+		if err != nil {
+			return nil, err
 		}
-		
-		if !found {
-			newEmbeddings = append(newEmbeddings, ai.Embedding{
-				Hash:      fragment.Hash,
-				Vector:    []float64{0}, // Mock vector
-				Model:     embeddingConfig.Model,
-				Dimension: embeddingConfig.Dimension,
+	}
+
+	// A batch erroring out (e.g. rate-limited) must not throw away the
+	// embeddings every other batch already computed, so genErr is tracked
+	// alongside newEmbeddings rather than short-circuiting on it.
+	newEmbeddings, genErr := g.generateBatches(ctx, backend, embeddingConfig, pending)
+
+	if storeErr := g.storeEmbeddings(ctx, repoMetadata, newEmbeddings); storeErr != nil && genErr == nil {
+		genErr = storeErr
+	}
+
+	result := &ai.EmbeddingResult{Embeddings: newEmbeddings, Success: genErr == nil}
+	if genErr != nil {
+		result.Error = genErr.Error()
+	}
+	return result, genErr
+}
+
+// storeEmbeddings commits each embedding through Store.GuardedUpdate so the
+// "check exists -> compute -> store" sequence stays atomic per hash even
+// when GenerateEmbeddings is called concurrently for the same repo. Writes
+// fan out bounded to GOMAXPROCS at a time, mirroring generateBatches.
+func (g *Generator) storeEmbeddings(ctx context.Context, repoMetadata types.RepoMetadata, embeddings []ai.Embedding) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+
+	concurrency := runtime.GOMAXPROCS(0)
+	if concurrency > len(embeddings) {
+		concurrency = len(embeddings)
+	}
+	sem := make(chan struct{}, concurrency)
+
+	errs := make([]error, len(embeddings))
+	done := make(chan struct{}, len(embeddings))
+	for i, embedding := range embeddings {
+		sem <- struct{}{}
+		go func(i int, embedding ai.Embedding) {
+			defer func() { <-sem; done <- struct{}{} }()
+			errs[i] = g.store.GuardedUpdate(ctx, embeddingKey(repoMetadata, embedding.Hash), func(current *ai.Embedding) (*ai.Embedding, error) {
+				if current != nil {
+					// A concurrent call already stored this hash; keep its value.
+					return current, nil
+				}
+				return &embedding, nil
 			})
+		}(i, embedding)
+	}
+	for range embeddings {
+		<-done
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return err
 		}
 	}
-	
-	err = g.store.StoreFragmentEmbeddings(ctx, embeddingConfig, repoMetadata, types.GraphQLTypes{Types: []string{"mocked"}})
+	return nil
+}
+
+// embeddingKey identifies a stored embedding by repo and content hash.
+func embeddingKey(repoMetadata types.RepoMetadata, hash string) string {
+	return repoMetadata.RepoName + ":" + repoMetadata.Branch + ":" + hash
+}
+
+// generateBatches chunks fragments into embeddingConfig.BatchSize-sized
+// groups and embeds them concurrently, bounded to GOMAXPROCS batches in
+// flight at once. Every batch that succeeds contributes its embeddings to
+// the returned slice regardless of whether a sibling batch errored; the
+// first error encountered, if any, is returned alongside them rather than
+// discarding the successful batches' results.
+func (g *Generator) generateBatches(ctx context.Context, backend EmbeddingBackend, embeddingConfig graphql_types.EmbeddingConfig, fragments []types.Fragment) ([]ai.Embedding, error) {
+	batchSize := embeddingConfig.BatchSize
+	if batchSize <= 0 {
+		batchSize = len(fragments)
+	}
+	batches := chunkFragments(fragments, batchSize)
+
+	concurrency := runtime.GOMAXPROCS(0)
+	if concurrency > len(batches) {
+		concurrency = len(batches)
+	}
+	sem := make(chan struct{}, concurrency)
+
+	results := make([][]ai.Embedding, len(batches))
+	errs := make([]error, len(batches))
+
+	done := make(chan struct{}, len(batches))
+	for i, batch := range batches {
+		sem <- struct{}{}
+		go func(i int, batch []types.Fragment) {
+			defer func() { <-sem; done <- struct{}{} }()
+			results[i], errs[i] = g.embedBatch(ctx, backend, embeddingConfig, batch)
+		}(i, batch)
+	}
+	for range batches {
+		<-done
+	}
+
+	var embeddings []ai.Embedding
+	var firstErr error
+	for i, err := range errs {
+		embeddings = append(embeddings, results[i]...)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return embeddings, firstErr
+}
+
+// embedBatch calls backend.Embed for a single batch and turns the resulting
+// vectors into ai.Embedding values, routing anything that comes back
+// missing, invalid, or the wrong dimension to the feedback channel instead
+// of failing the whole batch.
+func (g *Generator) embedBatch(ctx context.Context, backend EmbeddingBackend, embeddingConfig graphql_types.EmbeddingConfig, batch []types.Fragment) ([]ai.Embedding, error) {
+	texts := make([]string, len(batch))
+	for i, fragment := range batch {
+		texts[i] = fragment.Content
+	}
+
+	vectors, err := backend.Embed(ctx, embeddingConfig.Model, texts)
 	if err != nil {
+		for _, fragment := range batch {
+			g.emitFailure(EmbeddingFailure{Hash: fragment.Hash, Reason: FailureRateLimited, Err: err})
+		}
 		return nil, err
 	}
-	
-	return &ai.EmbeddingResult{Embeddings: newEmbeddings, Success: true}, nil
+
+	embeddings := make([]ai.Embedding, 0, len(batch))
+	for i, fragment := range batch {
+		if i >= len(vectors) || vectors[i] == nil {
+			g.emitFailure(EmbeddingFailure{
+				Hash:   fragment.Hash,
+				Reason: FailureInvalidInput,
+				Err:    fmt.Errorf("no vector returned for fragment %s", fragment.Hash),
+			})
+			continue
+		}
+
+		if embeddingConfig.Dimension > 0 && len(vectors[i]) != embeddingConfig.Dimension {
+			g.emitFailure(EmbeddingFailure{
+				Hash:   fragment.Hash,
+				Reason: FailureDimensionMismatch,
+				Err:    fmt.Errorf("expected dimension %d, got %d", embeddingConfig.Dimension, len(vectors[i])),
+			})
+			continue
+		}
+
+		embeddings = append(embeddings, ai.Embedding{
+			Hash:      fragment.Hash,
+			Vector:    float32sToFloat64s(vectors[i]),
+			Model:     embeddingConfig.Model,
+			Dimension: len(vectors[i]),
+		})
+	}
+
+	return embeddings, nil
+}
+
+func chunkFragments(fragments []types.Fragment, size int) [][]types.Fragment {
+	var chunks [][]types.Fragment
+	for i := 0; i < len(fragments); i += size {
+		end := i + size
+		if end > len(fragments) {
+			end = len(fragments)
+		}
+		chunks = append(chunks, fragments[i:end])
+	}
+	return chunks
+}
+
+func float32sToFloat64s(v []float32) []float64 {
+	out := make([]float64, len(v))
+	for i, f := range v {
+		out[i] = float64(f)
+	}
+	return out
 }
 
 func extractHashes(fragments []types.Fragment) []string {
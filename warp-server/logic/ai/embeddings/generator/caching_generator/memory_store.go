@@ -0,0 +1,103 @@
+package caching_generator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/warpdotdev/warp-server/model/types"
+	"github.com/warpdotdev/warp-server/model/types/ai"
+	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
+)
+
+// defaultMaxGuardedUpdateAttempts bounds how many times in all InMemoryStore
+// calls tryUpdate (the first call included) when it keeps losing a
+// compare-and-swap race, mirroring etcd3's optimistic-concurrency clients.
+const defaultMaxGuardedUpdateAttempts = 10
+
+// versionedEmbedding pairs a stored embedding with the resource version it
+// was written at, so GuardedUpdate can detect concurrent writers.
+type versionedEmbedding struct {
+	embedding ai.Embedding
+	version   int
+}
+
+// InMemoryStore is a Store implementation backed by a map, useful as a
+// reference implementation and in tests that need genuine (non-mocked)
+// concurrency rather than a scripted mock.
+type InMemoryStore struct {
+	// MaxAttempts bounds how many times in all tryUpdate is called, the
+	// first call included, when version conflicts keep occurring. Zero
+	// means defaultMaxGuardedUpdateAttempts.
+	MaxAttempts int
+
+	mu      sync.Mutex
+	entries map[string]versionedEmbedding
+}
+
+// NewInMemoryStore returns an empty InMemoryStore.
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{entries: make(map[string]versionedEmbedding)}
+}
+
+// CheckFragmentEmbeddingsExist implements Store.
+func (s *InMemoryStore) CheckFragmentEmbeddingsExist(ctx context.Context, embeddingConfig graphql_types.EmbeddingConfig, repoMetadata types.RepoMetadata, hashes []string, userUID string) ([]ai.ContentHashJbool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]ai.ContentHashJbool, len(hashes))
+	for i, hash := range hashes {
+		_, exists := s.entries[embeddingKey(repoMetadata, hash)]
+		result[i] = ai.ContentHashJbool{Hash: hash, Exists: exists}
+	}
+	return result, nil
+}
+
+// GuardedUpdate implements Store. It reads the entry currently stored under
+// key, offers it to tryUpdate, and commits the result only if no other
+// writer has touched key since the read. On a lost race it re-reads the
+// (now current) entry and retries tryUpdate, calling it at most MaxAttempts
+// times in all.
+func (s *InMemoryStore) GuardedUpdate(ctx context.Context, key string, tryUpdate func(current *ai.Embedding) (*ai.Embedding, error)) error {
+	maxAttempts := s.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxGuardedUpdateAttempts
+	}
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		s.mu.Lock()
+		current, ok := s.entries[key]
+		s.mu.Unlock()
+
+		var currentEmbedding *ai.Embedding
+		if ok {
+			currentEmbedding = &current.embedding
+		}
+
+		next, err := tryUpdate(currentEmbedding)
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			return nil
+		}
+
+		s.mu.Lock()
+		readVersion := 0
+		if ok {
+			readVersion = current.version
+		}
+		nowCurrent, stillThere := s.entries[key]
+		if stillThere != ok || (stillThere && nowCurrent.version != readVersion) {
+			// Someone else wrote key between our read and our commit;
+			// re-read and retry tryUpdate against the new value.
+			s.mu.Unlock()
+			continue
+		}
+		s.entries[key] = versionedEmbedding{embedding: *next, version: readVersion + 1}
+		s.mu.Unlock()
+		return nil
+	}
+
+	return fmt.Errorf("caching_generator: GuardedUpdate: exceeded %d attempts for key %q", maxAttempts, key)
+}
@@ -0,0 +1,117 @@
+package caching_generator
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/warpdotdev/warp-server/model/types"
+	"github.com/warpdotdev/warp-server/model/types/ai"
+	graphql_types "github.com/warpdotdev/warp-server/model/types/v2"
+)
+
+// fixedVectorBackend is a deterministic EmbeddingBackend stand-in for tests
+// that don't need a real provider.
+type fixedVectorBackend struct{}
+
+func (fixedVectorBackend) Embed(ctx context.Context, model string, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = []float32{1, 2, 3}
+	}
+	return vectors, nil
+}
+
+// TestGenerateEmbeddings_ConcurrentCallsForSameFragmentPersistExactlyOne
+// races two goroutines through the full "check exists -> compute -> store"
+// sequence of GenerateEmbeddings for the same fragment, rather than calling
+// InMemoryStore.GuardedUpdate directly, and asserts the race still lands
+// exactly one stored embedding.
+func TestGenerateEmbeddings_ConcurrentCallsForSameFragmentPersistExactlyOne(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryStore()
+	generator := NewWithBackend(store, fixedVectorBackend{})
+	repoMetadata := types.RepoMetadata{RepoName: "test-repo", Branch: "main"}
+	fragments := []types.Fragment{{Hash: "fragment1", Content: "shared content"}}
+	embeddingConfig := graphql_types.EmbeddingConfig{Provider: "mock", Model: "test-model"}
+
+	const callers = 2
+	var wg sync.WaitGroup
+	wg.Add(callers)
+	errs := make([]error, callers)
+
+	for i := 0; i < callers; i++ {
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = generator.GenerateEmbeddings(ctx, embeddingConfig, repoMetadata, fragments, "user123")
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("caller %d: GenerateEmbeddings returned error: %v", i, err)
+		}
+	}
+
+	existing, err := store.CheckFragmentEmbeddingsExist(ctx, embeddingConfig, repoMetadata, []string{"fragment1"}, "user123")
+	if err != nil {
+		t.Fatalf("CheckFragmentEmbeddingsExist returned error: %v", err)
+	}
+	if len(existing) != 1 || !existing[0].Exists {
+		t.Fatalf("expected exactly one persisted embedding for fragment1, got %+v", existing)
+	}
+
+	store.mu.Lock()
+	entryCount := len(store.entries)
+	store.mu.Unlock()
+	if entryCount != 1 {
+		t.Errorf("expected exactly 1 entry in the store, got %d", entryCount)
+	}
+}
+
+func TestInMemoryStore_GuardedUpdate_ConcurrentWritesConverge(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryStore()
+	repoMetadata := types.RepoMetadata{RepoName: "test-repo", Branch: "main"}
+	key := embeddingKey(repoMetadata, "fragment1")
+
+	const writers = 2
+	var wg sync.WaitGroup
+	wg.Add(writers)
+	errs := make([]error, writers)
+
+	for i := 0; i < writers; i++ {
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = store.GuardedUpdate(ctx, key, func(current *ai.Embedding) (*ai.Embedding, error) {
+				return &ai.Embedding{Hash: "fragment1", Vector: []float64{float64(i)}, Model: "test-model", Dimension: 1}, nil
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("writer %d: GuardedUpdate returned error: %v", i, err)
+		}
+	}
+
+	existing, err := store.CheckFragmentEmbeddingsExist(ctx, graphql_types.EmbeddingConfig{}, repoMetadata, []string{"fragment1"}, "user123")
+	if err != nil {
+		t.Fatalf("CheckFragmentEmbeddingsExist returned error: %v", err)
+	}
+	if len(existing) != 1 || !existing[0].Exists {
+		t.Fatalf("expected exactly one persisted embedding for fragment1, got %+v", existing)
+	}
+
+	store.mu.Lock()
+	entry, ok := store.entries[key]
+	store.mu.Unlock()
+	if !ok {
+		t.Fatalf("expected entry for key %q", key)
+	}
+	if entry.version != writers {
+		t.Errorf("expected version %d after %d writers, got %d", writers, writers, entry.version)
+	}
+}
@@ -0,0 +1,40 @@
+package caching_generator
+
+import (
+	"context"
+	"errors"
+)
+
+// ONNXRunner is implemented by whatever local inference runtime is wired up
+// (e.g. a sentence-transformers model loaded through an ONNX Runtime
+// binding). It's injected rather than linked directly so this package
+// doesn't need a cgo dependency to build.
+type ONNXRunner interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// ONNXBackend adapts an ONNXRunner to EmbeddingBackend.
+type ONNXBackend struct {
+	Runner ONNXRunner
+}
+
+// NewONNXBackend returns an ONNXBackend with no runner configured; set
+// Runner (or use WithRunner) before calling Embed.
+func NewONNXBackend() *ONNXBackend {
+	return &ONNXBackend{}
+}
+
+// WithRunner returns a copy of b that delegates to runner.
+func (b *ONNXBackend) WithRunner(runner ONNXRunner) *ONNXBackend {
+	return &ONNXBackend{Runner: runner}
+}
+
+// Embed implements EmbeddingBackend. The model argument is accepted for
+// interface compatibility with the other backends but ignored: a local
+// runner is wired up to a single model at process start.
+func (b *ONNXBackend) Embed(ctx context.Context, model string, texts []string) ([][]float32, error) {
+	if b.Runner == nil {
+		return nil, errors.New("onnx backend: no ONNXRunner configured")
+	}
+	return b.Runner.Embed(ctx, texts)
+}
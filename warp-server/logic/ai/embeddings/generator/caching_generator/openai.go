@@ -0,0 +1,85 @@
+package caching_generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// OpenAIBackend calls OpenAI's /v1/embeddings endpoint.
+type OpenAIBackend struct {
+	APIKey     string
+	BaseURL    string
+	HTTPClient *http.Client
+	MaxRetries int
+}
+
+// NewOpenAIBackend returns an OpenAIBackend authenticated with apiKey.
+func NewOpenAIBackend(apiKey string) *OpenAIBackend {
+	return &OpenAIBackend{
+		APIKey:     apiKey,
+		BaseURL:    "https://api.openai.com/v1",
+		HTTPClient: http.DefaultClient,
+		MaxRetries: 3,
+	}
+}
+
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+		Index     int       `json:"index"`
+	} `json:"data"`
+}
+
+// Embed implements EmbeddingBackend.
+func (b *OpenAIBackend) Embed(ctx context.Context, model string, texts []string) ([][]float32, error) {
+	var vectors [][]float32
+
+	err := withRetry(ctx, b.MaxRetries, func() error {
+		payload, err := json.Marshal(openAIEmbeddingRequest{Model: model, Input: texts})
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.BaseURL+"/embeddings", bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+b.APIKey)
+
+		resp, err := b.HTTPClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("openai embeddings request failed with status %d", resp.StatusCode)
+		}
+
+		var parsed openAIEmbeddingResponse
+		if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+			return err
+		}
+
+		result := make([][]float32, len(texts))
+		for _, d := range parsed.Data {
+			if d.Index < 0 || d.Index >= len(result) {
+				continue
+			}
+			result[d.Index] = d.Embedding
+		}
+		vectors = result
+		return nil
+	})
+
+	return vectors, err
+}
@@ -1,8 +1,16 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/warpdotdev/warp-server/fragment_embedding/metrics"
+	"github.com/warpdotdev/warp-server/server"
 )
 
 func main() {
@@ -10,13 +18,42 @@ func main() {
 	config := LoadConfig()
 
 	// Setup routes using the new handlers
-	http.HandleFunc("/", HomeHandler)
-	http.HandleFunc("/health", HealthHandler)
-	http.HandleFunc("/api/status", StatusHandler)
-	http.HandleFunc("/api/database/stats", DatabaseStatsHandler)
-	http.HandleFunc("/api/commands/recent", RecentCommandsHandler)
-	http.HandleFunc("/api/ai/queries/recent", RecentAIQueriesHandler)
-
-	log.Printf("Warp Server starting on port %s (Environment: %s)", config.Port, config.Environment)
-	log.Fatal(http.ListenAndServe(":"+config.Port, nil))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", HomeHandler)
+	mux.HandleFunc("/health", HealthHandler)
+	mux.HandleFunc("/api/status", StatusHandler)
+	mux.HandleFunc("/api/database/stats", DatabaseStatsHandler)
+	mux.HandleFunc("/api/commands/recent", RecentCommandsHandler)
+	mux.HandleFunc("/api/ai/queries/recent", RecentAIQueriesHandler)
+	mux.HandleFunc("/api/commands/import", ImportCommandsHandler)
+	mux.HandleFunc("/api/ai/queries/import", ImportAIQueriesHandler)
+	mux.Handle("/metrics", metrics.Handler())
+
+	srv := server.New(server.Config{
+		Addr:           ":" + config.Port,
+		Handler:        mux,
+		ReadTimeout:    config.ReadTimeout,
+		WriteTimeout:   config.WriteTimeout,
+		IdleTimeout:    config.IdleTimeout,
+		RequestTimeout: config.RequestTimeout,
+	})
+
+	go func() {
+		log.Printf("Warp Server starting on port %s (Environment: %s)", config.Port, config.Environment)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Warp Server failed: %v", err)
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+
+	log.Println("Warp Server shutting down...")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Warp Server shutdown did not complete cleanly: %v", err)
+	}
 }
@@ -2,17 +2,26 @@ package v2
 
 // EmbeddingConfig contains configuration for embedding generation
 type EmbeddingConfig struct {
-	Model         string  `json:"model"`
-	BatchSize     int     `json:"batch_size"`
-	MaxTokens     int     `json:"max_tokens,omitempty"`
-	Temperature   float64 `json:"temperature,omitempty"`
-	Dimension     int     `json:"dimension,omitempty"`
-	Normalize     bool    `json:"normalize,omitempty"`
-	Provider      string  `json:"provider,omitempty"`
+	Model       string  `json:"model"`
+	BatchSize   int     `json:"batch_size"`
+	MaxTokens   int     `json:"max_tokens,omitempty"`
+	Temperature float64 `json:"temperature,omitempty"`
+	Dimension   int     `json:"dimension,omitempty"`
+	Normalize   bool    `json:"normalize,omitempty"`
+	Provider    string  `json:"provider,omitempty"`
+
+	// ParallelThreshold is the fragment count above which GenerateEmbeddings
+	// switches from its serial path to a worker pool. Zero or negative
+	// means the caller wants the package default.
+	ParallelThreshold int `json:"parallel_threshold,omitempty"`
+	// MaxConcurrency bounds how many shards GenerateEmbeddings processes at
+	// once in its parallel path. Zero or negative means GOMAXPROCS.
+	MaxConcurrency int `json:"max_concurrency,omitempty"`
 }
 
 // GenerateCodeEmbeddingResult represents the result of code embedding generation
 type GenerateCodeEmbeddingResult struct {
-	Hash    string `json:"hash"`
-	Success bool   `json:"success"`
+	Hash    string    `json:"hash"`
+	Success bool      `json:"success"`
+	Vector  []float32 `json:"vector,omitempty"`
 }
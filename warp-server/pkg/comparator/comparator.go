@@ -0,0 +1,54 @@
+// Package comparator provides a small, reusable ordering abstraction so
+// callers can compose sort order (by field, by direction) without hand
+// writing a sort.Interface or less-func for every combination.
+package comparator
+
+import (
+	"strings"
+	"time"
+)
+
+// Comparator compares two values, returning a negative number if a sorts
+// before b, a positive number if a sorts after b, and zero if they are
+// equal. Implementations are expected to know how to type-assert a and b
+// themselves, the same way sort.Interface callers know their element type.
+type Comparator func(a, b interface{}) int
+
+// CompareInts is a Comparator for int values.
+func CompareInts(a, b interface{}) int {
+	ai, bi := a.(int), b.(int)
+	switch {
+	case ai < bi:
+		return -1
+	case ai > bi:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// CompareStrings is a Comparator for string values.
+func CompareStrings(a, b interface{}) int {
+	return strings.Compare(a.(string), b.(string))
+}
+
+// CompareTimestamps is a Comparator for time.Time values.
+func CompareTimestamps(a, b interface{}) int {
+	at, bt := a.(time.Time), b.(time.Time)
+	switch {
+	case at.Before(bt):
+		return -1
+	case at.After(bt):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ReverseComparator wraps cmp so that it orders descending instead of
+// ascending (or vice versa).
+func ReverseComparator(cmp Comparator) Comparator {
+	return func(a, b interface{}) int {
+		return -cmp(a, b)
+	}
+}
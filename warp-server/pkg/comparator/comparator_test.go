@@ -0,0 +1,44 @@
+package comparator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompareInts(t *testing.T) {
+	if CompareInts(1, 2) >= 0 {
+		t.Errorf("expected 1 < 2")
+	}
+	if CompareInts(2, 1) <= 0 {
+		t.Errorf("expected 2 > 1")
+	}
+	if CompareInts(1, 1) != 0 {
+		t.Errorf("expected 1 == 1")
+	}
+}
+
+func TestCompareStrings(t *testing.T) {
+	if CompareStrings("a", "b") >= 0 {
+		t.Errorf("expected a < b")
+	}
+}
+
+func TestCompareTimestamps(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	if CompareTimestamps(now, later) >= 0 {
+		t.Errorf("expected now < later")
+	}
+	if CompareTimestamps(later, now) <= 0 {
+		t.Errorf("expected later > now")
+	}
+}
+
+func TestReverseComparator(t *testing.T) {
+	reversed := ReverseComparator(CompareInts)
+
+	if reversed(1, 2) <= 0 {
+		t.Errorf("expected reversed comparator to order 1 after 2")
+	}
+}
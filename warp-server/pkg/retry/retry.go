@@ -0,0 +1,46 @@
+// Package retry provides a small, reusable exponential-backoff retry helper
+// so callers making flaky network calls (HTTP embedding providers, ...)
+// don't each hand-roll their own backoff loop.
+package retry
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Do calls fn up to maxAttempts times, backing off exponentially (with
+// jitter) between attempts, and gives up early if ctx is cancelled.
+func Do(ctx context.Context, maxAttempts int, fn func() error) error {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		lastErr = fn()
+		if lastErr == nil {
+			return nil
+		}
+
+		if attempt == maxAttempts-1 {
+			break
+		}
+
+		backoff := (1 << uint(attempt)) * 100 * time.Millisecond
+		jitter := time.Duration(rand.Int63n(int64(backoff)/2 + 1))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff + jitter):
+		}
+	}
+
+	return fmt.Errorf("giving up after %d attempts: %w", maxAttempts, lastErr)
+}
@@ -0,0 +1,69 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDo_SucceedsWithoutRetryingOnFirstSuccess(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), 3, func() error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestDo_RetriesUntilSuccess(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), 3, func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("transient failure")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestDo_GivesUpAfterMaxAttempts(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), 2, func() error {
+		calls++
+		return errors.New("persistent failure")
+	})
+	if err == nil {
+		t.Fatal("expected an error after exhausting all attempts")
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestDo_StopsEarlyWhenContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	err := Do(ctx, 5, func() error {
+		calls++
+		return errors.New("failure")
+	})
+	if err == nil {
+		t.Fatal("expected an error for a cancelled context")
+	}
+	if calls != 0 {
+		t.Errorf("calls = %d, want 0 (should not call fn once ctx is already done)", calls)
+	}
+}
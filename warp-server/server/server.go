@@ -0,0 +1,66 @@
+// Package server wraps http.Server with the timeouts and request-scoped
+// cancellation that the default net/http zero values leave out, so a slow
+// downstream call (a hung SQLite PRAGMA, a wedged query) can't pin a
+// connection open forever.
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Config configures a Server.
+type Config struct {
+	Addr    string
+	Handler http.Handler
+
+	// ReadTimeout, WriteTimeout, and IdleTimeout are passed straight through
+	// to the underlying http.Server.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+
+	// RequestTimeout bounds how long a single request's Handler may run. It
+	// is enforced by deriving a context.WithTimeout from each request's
+	// context, independent of ReadTimeout/WriteTimeout (which only bound the
+	// surrounding connection I/O). Zero disables the deadline.
+	RequestTimeout time.Duration
+}
+
+// Server is an http.Server with a deadline middleware installed in front of
+// its handler.
+type Server struct {
+	*http.Server
+}
+
+// New builds a Server from cfg. Handlers registered on cfg.Handler receive a
+// request whose context is cancelled once RequestTimeout elapses.
+func New(cfg Config) *Server {
+	handler := cfg.Handler
+	if cfg.RequestTimeout > 0 {
+		handler = withRequestDeadline(handler, cfg.RequestTimeout)
+	}
+
+	return &Server{
+		Server: &http.Server{
+			Addr:         cfg.Addr,
+			Handler:      handler,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			IdleTimeout:  cfg.IdleTimeout,
+		},
+	}
+}
+
+// withRequestDeadline wraps next so every request runs under a
+// context.WithTimeout(r.Context(), timeout), letting handlers thread that
+// deadline into db.QueryContext/db.ExecContext calls.
+func withRequestDeadline(next http.Handler, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/warpdotdev/warp-server/pkg/comparator"
+)
+
+// sortTerm is one parsed segment of a ?sort=field,-field query param: a
+// field name plus whether it should be applied in descending order.
+type sortTerm struct {
+	field string
+	desc  bool
+}
+
+// parseSortSpec parses a comma-separated ?sort= value like
+// "start_ts,-exit_code" into an ordered list of sortTerms. A leading "-"
+// marks a field descending. Empty segments are ignored.
+func parseSortSpec(spec string) []sortTerm {
+	if spec == "" {
+		return nil
+	}
+
+	var terms []sortTerm
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		term := sortTerm{field: part}
+		if strings.HasPrefix(part, "-") {
+			term.desc = true
+			term.field = strings.TrimPrefix(part, "-")
+		}
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+func intOrZero(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+// commandFieldComparator resolves a ?sort= field name to a Comparator over
+// two Command values. Unknown field names are reported via ok=false so
+// callers can ignore them rather than sorting on a nonexistent field.
+func commandFieldComparator(field string) (cmp comparator.Comparator, ok bool) {
+	switch field {
+	case "start_ts":
+		return func(a, b interface{}) int {
+			return comparator.CompareTimestamps(timeOrZero(a.(Command).StartTs), timeOrZero(b.(Command).StartTs))
+		}, true
+	case "exit_code":
+		return func(a, b interface{}) int {
+			return comparator.CompareInts(intOrZero(a.(Command).ExitCode), intOrZero(b.(Command).ExitCode))
+		}, true
+	case "command":
+		return func(a, b interface{}) int {
+			return comparator.CompareStrings(a.(Command).Command, b.(Command).Command)
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// aiQueryFieldComparator is the AIQuery equivalent of commandFieldComparator.
+func aiQueryFieldComparator(field string) (cmp comparator.Comparator, ok bool) {
+	switch field {
+	case "start_ts":
+		return func(a, b interface{}) int {
+			return comparator.CompareTimestamps(timeOrZero(a.(AIQuery).StartTs), timeOrZero(b.(AIQuery).StartTs))
+		}, true
+	case "output_status":
+		return func(a, b interface{}) int {
+			return comparator.CompareStrings(a.(AIQuery).OutputStatus, b.(AIQuery).OutputStatus)
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// sortCommands orders commands in place according to terms, applying each
+// comparator in turn (like a multi-column ORDER BY) until one reports a
+// difference. Unknown field names are skipped.
+func sortCommands(commands []Command, terms []sortTerm) {
+	var cmps []comparator.Comparator
+	for _, term := range terms {
+		cmp, ok := commandFieldComparator(term.field)
+		if !ok {
+			continue
+		}
+		if term.desc {
+			cmp = comparator.ReverseComparator(cmp)
+		}
+		cmps = append(cmps, cmp)
+	}
+
+	sort.SliceStable(commands, func(i, j int) bool {
+		for _, cmp := range cmps {
+			if c := cmp(commands[i], commands[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+}
+
+// sortAIQueries is the AIQuery equivalent of sortCommands.
+func sortAIQueries(queries []AIQuery, terms []sortTerm) {
+	var cmps []comparator.Comparator
+	for _, term := range terms {
+		cmp, ok := aiQueryFieldComparator(term.field)
+		if !ok {
+			continue
+		}
+		if term.desc {
+			cmp = comparator.ReverseComparator(cmp)
+		}
+		cmps = append(cmps, cmp)
+	}
+
+	sort.SliceStable(queries, func(i, j int) bool {
+		for _, cmp := range cmps {
+			if c := cmp(queries[i], queries[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSortSpec(t *testing.T) {
+	got := parseSortSpec("start_ts,-exit_code")
+	want := []sortTerm{
+		{field: "start_ts", desc: false},
+		{field: "exit_code", desc: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseSortSpec() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("term %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseSortSpec_EmptySpec(t *testing.T) {
+	if got := parseSortSpec(""); got != nil {
+		t.Errorf("parseSortSpec(\"\") = %+v, want nil", got)
+	}
+}
+
+func TestParseSortSpec_SkipsEmptyAndWhitespaceSegments(t *testing.T) {
+	got := parseSortSpec(" , start_ts ,, -exit_code ,  ")
+	want := []sortTerm{
+		{field: "start_ts", desc: false},
+		{field: "exit_code", desc: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseSortSpec() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("term %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseSortSpec_PassesThroughUnknownFields(t *testing.T) {
+	got := parseSortSpec("bogus_field")
+	want := []sortTerm{{field: "bogus_field", desc: false}}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Errorf("parseSortSpec(\"bogus_field\") = %+v, want %+v", got, want)
+	}
+}
+
+func cmdAt(t time.Time, exitCode int, command string) Command {
+	ec := exitCode
+	return Command{Command: command, ExitCode: &ec, StartTs: &t}
+}
+
+func TestSortCommands_MultiFieldChainedOrdering(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	commands := []Command{
+		cmdAt(base, 1, "b"),
+		cmdAt(base, 0, "a"),
+		cmdAt(base.Add(time.Hour), 0, "c"),
+	}
+
+	sortCommands(commands, parseSortSpec("start_ts,exit_code"))
+
+	want := []string{"a", "b", "c"}
+	for i, w := range want {
+		if commands[i].Command != w {
+			t.Errorf("commands[%d].Command = %q, want %q", i, commands[i].Command, w)
+		}
+	}
+}
+
+func TestSortCommands_DescendingOrder(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	commands := []Command{
+		cmdAt(base, 0, "oldest"),
+		cmdAt(base.Add(2*time.Hour), 0, "newest"),
+		cmdAt(base.Add(time.Hour), 0, "middle"),
+	}
+
+	sortCommands(commands, parseSortSpec("-start_ts"))
+
+	want := []string{"newest", "middle", "oldest"}
+	for i, w := range want {
+		if commands[i].Command != w {
+			t.Errorf("commands[%d].Command = %q, want %q", i, commands[i].Command, w)
+		}
+	}
+}
+
+func TestSortCommands_StableWhenComparatorsTie(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	commands := []Command{
+		cmdAt(base, 0, "first"),
+		cmdAt(base, 0, "second"),
+		cmdAt(base, 0, "third"),
+	}
+
+	sortCommands(commands, parseSortSpec("start_ts"))
+
+	want := []string{"first", "second", "third"}
+	for i, w := range want {
+		if commands[i].Command != w {
+			t.Errorf("commands[%d].Command = %q, want %q (order should be unchanged)", i, commands[i].Command, w)
+		}
+	}
+}
+
+func TestSortCommands_UnknownFieldSegmentsAreSkipped(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	commands := []Command{
+		cmdAt(base, 0, "b"),
+		cmdAt(base.Add(-time.Hour), 0, "a"),
+	}
+
+	sortCommands(commands, parseSortSpec("bogus_field,start_ts"))
+
+	want := []string{"a", "b"}
+	for i, w := range want {
+		if commands[i].Command != w {
+			t.Errorf("commands[%d].Command = %q, want %q", i, commands[i].Command, w)
+		}
+	}
+}
+
+func queryAt(t time.Time, status, id string) AIQuery {
+	return AIQuery{ExchangeId: id, StartTs: &t, OutputStatus: status}
+}
+
+func TestSortAIQueries_MultiFieldChainedOrdering(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	queries := []AIQuery{
+		queryAt(base, "success", "b"),
+		queryAt(base, "error", "a"),
+		queryAt(base.Add(time.Hour), "success", "c"),
+	}
+
+	sortAIQueries(queries, parseSortSpec("start_ts,output_status"))
+
+	want := []string{"a", "b", "c"}
+	for i, w := range want {
+		if queries[i].ExchangeId != w {
+			t.Errorf("queries[%d].ExchangeId = %q, want %q", i, queries[i].ExchangeId, w)
+		}
+	}
+}
+
+func TestSortAIQueries_DescendingOrder(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	queries := []AIQuery{
+		queryAt(base, "success", "oldest"),
+		queryAt(base.Add(2*time.Hour), "success", "newest"),
+		queryAt(base.Add(time.Hour), "success", "middle"),
+	}
+
+	sortAIQueries(queries, parseSortSpec("-start_ts"))
+
+	want := []string{"newest", "middle", "oldest"}
+	for i, w := range want {
+		if queries[i].ExchangeId != w {
+			t.Errorf("queries[%d].ExchangeId = %q, want %q", i, queries[i].ExchangeId, w)
+		}
+	}
+}
+
+func TestSortAIQueries_StableWhenComparatorsTie(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	queries := []AIQuery{
+		queryAt(base, "success", "first"),
+		queryAt(base, "success", "second"),
+		queryAt(base, "success", "third"),
+	}
+
+	sortAIQueries(queries, parseSortSpec("start_ts"))
+
+	want := []string{"first", "second", "third"}
+	for i, w := range want {
+		if queries[i].ExchangeId != w {
+			t.Errorf("queries[%d].ExchangeId = %q, want %q (order should be unchanged)", i, queries[i].ExchangeId, w)
+		}
+	}
+}
+
+func TestSortAIQueries_UnknownFieldSegmentsAreSkipped(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	queries := []AIQuery{
+		queryAt(base, "success", "b"),
+		queryAt(base.Add(-time.Hour), "success", "a"),
+	}
+
+	sortAIQueries(queries, parseSortSpec("bogus_field,start_ts"))
+
+	want := []string{"a", "b"}
+	for i, w := range want {
+		if queries[i].ExchangeId != w {
+			t.Errorf("queries[%d].ExchangeId = %q, want %q", i, queries[i].ExchangeId, w)
+		}
+	}
+}